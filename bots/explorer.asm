@@ -0,0 +1,20 @@
+; Walks straight ahead until something is in the way, then turns. Every few
+; turns it goes left instead of right so it doesn't circle the same block.
+; r0 is what is ahead and r1 counts the turns.
+loop:
+    look r0
+    jeq r0 dirt1 ahead
+    jeq r0 dirt2 ahead
+    jeq r0 rock ahead
+    add r1 1
+    set r2 r1
+    mod r2 3
+    jeq r2 0 left
+    turn right
+    jmp loop
+left:
+    turn left
+    jmp loop
+ahead:
+    move
+    jmp loop
@@ -0,0 +1,56 @@
+#!/usr/bin/env python3
+"""Plays a few episodes with random actions through the gym server.
+
+A starting point for training: swap the random choice for a policy.
+
+    python3 bots/gym_random.py go run ./src/cmd/gym -reward torch=1,explore=0.1
+"""
+import json
+import random
+import subprocess
+import sys
+
+
+class Env:
+    def __init__(self, command):
+        self.proc = subprocess.Popen(command, stdin=subprocess.PIPE, stdout=subprocess.PIPE, text=True)
+
+    def call(self, **request):
+        self.proc.stdin.write(json.dumps(request) + "\n")
+        self.proc.stdin.flush()
+        response = json.loads(self.proc.stdout.readline())
+        if "error" in response:
+            raise RuntimeError(response["error"])
+        return response
+
+    def spec(self):
+        return self.call(command="spec")["spec"]
+
+    def reset(self, seed):
+        return self.call(command="reset", seed=seed)["observation"]
+
+    def step(self, action):
+        step = self.call(command="step", action=action)["step"]
+        return step["observation"], step["reward"], step["done"], step["truncated"], step["info"]
+
+    def close(self):
+        self.proc.stdin.write(json.dumps({"command": "close"}) + "\n")
+        self.proc.stdin.close()
+        self.proc.wait()
+
+
+def main():
+    env = Env(sys.argv[1:] or ["go", "run", "./src/cmd/gym"])
+    spec = env.spec()
+    for seed in range(3):
+        env.reset(seed)
+        total, done, truncated = 0.0, False, False
+        while not (done or truncated):
+            _, reward, done, truncated, info = env.step(random.randrange(spec["actions"]))
+            total += reward
+        print(f"seed={seed} steps={info['steps']} reward={total:.2f} reached={'yes' if done else 'no'}")
+    env.close()
+
+
+if __name__ == "__main__":
+    main()
@@ -0,0 +1,35 @@
+#!/usr/bin/env python3
+"""Walks towards the nearest torch it can see, or wanders if it can't see one.
+
+Each line on stdin is an observation and each line written to stdout is the
+reply, see "Bots" in the README.
+"""
+import json
+import random
+import sys
+
+STEPS = {"north": (0, -1), "south": (0, 1), "east": (1, 0), "west": (-1, 0)}
+
+for line in sys.stdin:
+    obs = json.loads(line)
+    x, y = obs["position"]["x"], obs["position"]["y"]
+    cells = {(c["x"], c["y"]): c for c in obs["cells"]}
+    torches = [p for p, c in cells.items() if "torch" in c["things"]]
+
+    def passable(direction):
+        dx, dy = STEPS[direction]
+        cell = cells.get((x + dx, y + dy))
+        return cell is not None and cell["passable"]
+
+    options = [d for d in STEPS if passable(d)]
+    if torches:
+        tx, ty = min(torches, key=lambda p: abs(p[0] - x) + abs(p[1] - y))
+        closer = [d for d in options
+                  if abs(tx - x - STEPS[d][0]) + abs(ty - y - STEPS[d][1]) < abs(tx - x) + abs(ty - y)]
+        options = closer or options
+
+    if options:
+        reply = {"action": "move", "direction": random.choice(options)}
+    else:
+        reply = {"action": "wait"}
+    print(json.dumps(reply), flush=True)
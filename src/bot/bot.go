@@ -0,0 +1,333 @@
+// Package bot runs bots written in any language as processes of their own.
+// Every tick a bot is sent a line of JSON with what its being can observe and
+// answers with a line of JSON saying what the being does.
+package bot
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"gobotworld/src/world"
+	"gobotworld/src/world/object"
+	"log"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultTimeout is how long a bot has to answer before its being waits out
+// the tick.
+const DefaultTimeout = 100 * time.Millisecond
+
+// startupTimeout is how long a bot has to answer its first observation, which
+// is also when interpreters are still loading.
+const startupTimeout = 2 * time.Second
+
+// Position is a point on the map.
+type Position struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+// Cell is one cell around a bot. Things are the names used in config files,
+// and there are none in cells it can't see.
+type Cell struct {
+	X        int      `json:"x"`
+	Y        int      `json:"y"`
+	Things   []string `json:"things"`
+	Passable bool     `json:"passable"`
+	Visible  bool     `json:"visible"`
+}
+
+// Observation is the line sent to a bot each tick. Cycle is "day" or "night"
+// and Facing is one of the directions a Reply can give.
+type Observation struct {
+	Tick     int      `json:"tick"`
+	Cycle    string   `json:"cycle"`
+	Position Position `json:"position"`
+	Facing   string   `json:"facing"`
+	Light    int      `json:"light"`
+	Carrying bool     `json:"carrying"`
+	Cells    []Cell   `json:"cells"`
+}
+
+// Reply is the line a bot answers with. Action is "move", "face", "take",
+// "place" or "wait", and Direction is "north", "south", "east" or "west" for
+// all but the last.
+type Reply struct {
+	Action    string `json:"action"`
+	Direction string `json:"direction,omitempty"`
+}
+
+var directions = []object.Direction{object.North, object.South, object.East, object.West}
+
+func directionName(d object.Direction) string {
+	return strings.ToLower(d.String())
+}
+
+func parseDirection(name string) (object.Direction, error) {
+	for _, d := range directions {
+		if directionName(d) == name {
+			return d, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown direction %q", name)
+}
+
+func encode(obs world.Observation) Observation {
+	cycle := "day"
+	if obs.Cycle == object.NightTime {
+		cycle = "night"
+	}
+	out := Observation{
+		Tick:     obs.Tick,
+		Cycle:    cycle,
+		Position: Position{X: obs.Location.X, Y: obs.Location.Y},
+		Facing:   directionName(obs.Facing),
+		Light:    obs.Light,
+		Carrying: obs.Carrying,
+		Cells:    make([]Cell, 0, len(obs.Cells)),
+	}
+	for _, c := range obs.Cells {
+		cell := Cell{X: c.Point.X, Y: c.Point.Y, Things: []string{}, Passable: c.Passable, Visible: c.Visible}
+		for _, t := range c.Things {
+			cell.Things = append(cell.Things, t.String())
+		}
+		out.Cells = append(out.Cells, cell)
+	}
+	return out
+}
+
+// action turns the reply into the action it asks for.
+func (r Reply) action() (world.Action, error) {
+	switch r.Action {
+	case "wait":
+		return world.WaitAction(nil), nil
+	case "move", "face", "take", "place":
+		d, err := parseDirection(r.Direction)
+		if err != nil {
+			return world.Action{}, err
+		}
+		switch r.Action {
+		case "move":
+			return world.MoveAction(nil, d), nil
+		case "take":
+			return world.TakeAction(nil, d), nil
+		case "place":
+			return world.PlaceAction(nil, d), nil
+		}
+		return world.FaceAction(nil, d), nil
+	}
+	return world.Action{}, fmt.Errorf("unknown action %q", r.Action)
+}
+
+// Process is a world.Controller backed by a bot process. A bot that is slow,
+// talks nonsense or crashes only costs its own being its turn, the world
+// carries on without it. Everything the bot writes to stderr goes to the log.
+type Process struct {
+	Name    string
+	logger  *log.Logger
+	timeout time.Duration
+	cmd     *exec.Cmd
+	outbox  chan []byte
+	replies chan []byte
+	done    chan struct{}
+	started bool
+	failed  bool
+}
+
+// Start launches a bot. Act must only be called from the goroutine that ticks
+// the world.
+func Start(logger *log.Logger, timeout time.Duration, command string, args ...string) (*Process, error) {
+	cmd := exec.Command(command, args...)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		return nil, err
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting bot %s: %w", command, err)
+	}
+
+	p := &Process{
+		Name:    filepath.Base(command),
+		logger:  logger,
+		timeout: timeout,
+		cmd:     cmd,
+		outbox:  make(chan []byte, 1),
+		replies: make(chan []byte, 16),
+		done:    make(chan struct{}),
+	}
+
+	var reading sync.WaitGroup
+	reading.Add(2)
+	go func() {
+		defer reading.Done()
+		scanner := bufio.NewScanner(stderr)
+		for scanner.Scan() {
+			logger.Printf("Bot %s: %s", p.Name, scanner.Text())
+		}
+	}()
+	go func() {
+		defer reading.Done()
+		defer close(p.replies)
+		scanner := bufio.NewScanner(stdout)
+		scanner.Buffer(nil, 1<<20)
+		for scanner.Scan() {
+			p.replies <- slices.Clone(scanner.Bytes())
+		}
+	}()
+	go func() {
+		// Wait can only be called once the pipes have been read to the end
+		reading.Wait()
+		err := cmd.Wait()
+		logger.Printf("Bot %s exited: %v", p.Name, err)
+		close(p.done)
+	}()
+	go func() {
+		// Writes go through their own goroutine so a bot that stops reading
+		// can't block the world
+		for line := range p.outbox {
+			if _, err := stdin.Write(line); err != nil {
+				logger.Printf("Bot %s can't be written to: %v", p.Name, err)
+			}
+		}
+		stdin.Close()
+	}()
+	return p, nil
+}
+
+// Act sends the bot the observation and returns the action it replies with.
+// The being waits if the bot takes longer than the timeout or its reply
+// doesn't make sense, and for good once the bot has exited.
+func (p *Process) Act(obs world.Observation) world.Action {
+	wait := world.WaitAction(nil)
+	if p.failed {
+		return wait
+	}
+
+	// Replies that came too late for their own tick are dropped
+	for stale := true; stale; {
+		select {
+		case line, ok := <-p.replies:
+			if !ok {
+				return p.fail()
+			}
+			p.logger.Printf("Bot %s replied too late with %s", p.Name, line)
+		default:
+			stale = false
+		}
+	}
+
+	line, err := json.Marshal(encode(obs))
+	if err != nil {
+		p.logger.Printf("Bot %s can't be sent tick %d: %v", p.Name, obs.Tick, err)
+		return wait
+	}
+	select {
+	case p.outbox <- append(line, '\n'):
+	default:
+		p.logger.Printf("Bot %s hasn't read the last observation, skipping tick %d", p.Name, obs.Tick)
+		return wait
+	}
+
+	timeout := p.timeout
+	if !p.started {
+		timeout = max(timeout, startupTimeout)
+		p.started = true
+	}
+	select {
+	case line, ok := <-p.replies:
+		if !ok {
+			return p.fail()
+		}
+		var reply Reply
+		if err := json.Unmarshal(line, &reply); err != nil {
+			p.logger.Printf("Bot %s sent %q: %v", p.Name, line, err)
+			return wait
+		}
+		a, err := reply.action()
+		if err != nil {
+			p.logger.Printf("Bot %s sent %q: %v", p.Name, line, err)
+			return wait
+		}
+		return a
+	case <-time.After(timeout):
+		p.logger.Printf("Bot %s took longer than %s on tick %d", p.Name, timeout, obs.Tick)
+		return wait
+	}
+}
+
+func (p *Process) fail() world.Action {
+	p.logger.Printf("Bot %s has stopped, its being will wait from now on", p.Name)
+	p.failed = true
+	return world.WaitAction(nil)
+}
+
+// Close ends the bot's input and waits for it to exit, killing it if it takes
+// longer than the timeout.
+func (p *Process) Close() {
+	if p.outbox == nil {
+		return
+	}
+	close(p.outbox)
+	p.outbox = nil
+	select {
+	case <-p.done:
+	case <-time.After(p.timeout):
+		p.cmd.Process.Kill()
+		<-p.done
+	}
+}
+
+// Commands collects the bot commands of a flag that can be given more than
+// once.
+type Commands []string
+
+func (c *Commands) String() string {
+	return strings.Join(*c, ", ")
+}
+
+func (c *Commands) Set(command string) error {
+	if len(strings.Fields(command)) == 0 {
+		return fmt.Errorf("bot command is empty")
+	}
+	*c = append(*c, command)
+	return nil
+}
+
+// Attach starts a bot for each command, split on spaces, and hands it a being
+// in the order the beings are given. The bots must be closed once the world is
+// done with.
+func Attach(logger *log.Logger, w world.World, beings []*object.Character, timeout time.Duration, commands Commands) ([]*Process, error) {
+	if len(commands) > len(beings) {
+		return nil, fmt.Errorf("%d bots but only %d beings for them", len(commands), len(beings))
+	}
+
+	var bots []*Process
+	for i, command := range commands {
+		fields := strings.Fields(command)
+		p, err := Start(logger, timeout, fields[0], fields[1:]...)
+		if err != nil {
+			for _, started := range bots {
+				started.Close()
+			}
+			return nil, err
+		}
+		p.Name = command
+		logger.Printf("Bot %s controls being %d", p.Name, beings[i].Ident().Index)
+		w.SetController(beings[i], p)
+		bots = append(bots, p)
+	}
+	return bots, nil
+}
@@ -0,0 +1,195 @@
+package bot
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"gobotworld/src/sim"
+	"gobotworld/src/world"
+	"gobotworld/src/world/object"
+	"image"
+	"io"
+	"log"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// botMode makes the test binary run as a bot instead of the tests, see testBot.
+const botMode = "GOBOTWORLD_TEST_BOT"
+
+func TestMain(m *testing.M) {
+	if mode := os.Getenv(botMode); mode != "" {
+		testBot(mode)
+		os.Exit(0)
+	}
+	os.Exit(m.Run())
+}
+
+// testBot answers every observation in a way that depends on mode.
+func testBot(mode string) {
+	if mode == "deaf" {
+		time.Sleep(time.Minute)
+		return
+	}
+	scanner := bufio.NewScanner(os.Stdin)
+	scanner.Buffer(nil, 1<<20)
+	for scanner.Scan() {
+		var obs Observation
+		if err := json.Unmarshal(scanner.Bytes(), &obs); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		switch mode {
+		case "east":
+			fmt.Println(`{"action":"move","direction":"east"}`)
+		case "chatty":
+			fmt.Fprintf(os.Stderr, "at %d,%d on tick %d\n", obs.Position.X, obs.Position.Y, obs.Tick)
+			fmt.Println(`{"action":"face","direction":"south"}`)
+		case "slow":
+			time.Sleep(200 * time.Millisecond)
+			fmt.Println(`{"action":"move","direction":"east"}`)
+		case "garbage":
+			fmt.Println(`{"action":"dance"}`)
+		case "crash":
+			os.Exit(3)
+		}
+	}
+}
+
+func startBot(t *testing.T, mode string, logger *log.Logger, timeout time.Duration) *Process {
+	t.Setenv(botMode, mode)
+	p, err := Start(logger, timeout, os.Args[0])
+	require.NoError(t, err)
+	t.Cleanup(p.Close)
+	return p
+}
+
+func testWorld() world.World {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	return world.InitWorld(logger, 20, 20, world.EmptyConfig().WithSeed(1).WithNPCs(
+		world.NPCSpawn{Type: object.EnemyType, Location: &image.Point{X: 2, Y: 2}},
+	))
+}
+
+func TestEncode(t *testing.T) {
+	w := testWorld()
+	obs := encode(w.Observe(w.Player))
+	assert.Equal(t, "day", obs.Cycle)
+	assert.Equal(t, "north", obs.Facing)
+	assert.Equal(t, Position{X: w.Player.Location.X, Y: w.Player.Location.Y}, obs.Position)
+	require.Len(t, obs.Cells, (world.ObservationRadius*2+1)*(world.ObservationRadius*2+1))
+	centre := obs.Cells[len(obs.Cells)/2]
+	assert.Equal(t, obs.Position, Position{X: centre.X, Y: centre.Y})
+	assert.Contains(t, centre.Things, "player")
+}
+
+func TestReplyAction(t *testing.T) {
+	a, err := Reply{Action: "move", Direction: "west"}.action()
+	require.NoError(t, err)
+	assert.Equal(t, world.MoveAction(nil, object.West), a)
+
+	a, err = Reply{Action: "face", Direction: "north"}.action()
+	require.NoError(t, err)
+	assert.Equal(t, world.FaceAction(nil, object.North), a)
+
+	a, err = Reply{Action: "take", Direction: "east"}.action()
+	require.NoError(t, err)
+	assert.Equal(t, world.TakeAction(nil, object.East), a)
+
+	a, err = Reply{Action: "place", Direction: "south"}.action()
+	require.NoError(t, err)
+	assert.Equal(t, world.PlaceAction(nil, object.South), a)
+
+	_, err = Reply{Action: "move", Direction: "up"}.action()
+	assert.Error(t, err)
+	_, err = Reply{Action: "jump"}.action()
+	assert.Error(t, err)
+}
+
+func TestBotDrivesItsBeing(t *testing.T) {
+	w := testWorld()
+	p := startBot(t, "east", log.New(io.Discard, "", 0), time.Second)
+	w.SetController(w.Player, p)
+
+	start := *w.Player.Location
+	for range 3 {
+		sim.Step(w)
+	}
+	assert.Equal(t, start.Add(image.Point{X: 3}), *w.Player.Location)
+}
+
+func TestSlowBotsWait(t *testing.T) {
+	var log bytes.Buffer
+	p := startBot(t, "slow", logTo(&log), 20*time.Millisecond)
+	w := testWorld()
+
+	assert.Equal(t, world.MoveAction(nil, object.East), p.Act(w.Observe(w.Player)), "Bots have longer to start up")
+	assert.Equal(t, world.WaitAction(nil), p.Act(w.Observe(w.Player)))
+	time.Sleep(300 * time.Millisecond)
+	assert.Equal(t, world.WaitAction(nil), p.Act(w.Observe(w.Player)), "Late replies aren't used for the next tick")
+	p.Close()
+	assert.Contains(t, log.String(), "replied too late")
+}
+
+func TestBadRepliesWait(t *testing.T) {
+	p := startBot(t, "garbage", log.New(io.Discard, "", 0), time.Second)
+	w := testWorld()
+	assert.Equal(t, world.WaitAction(nil), p.Act(w.Observe(w.Player)))
+}
+
+func TestCrashedBotsWait(t *testing.T) {
+	var log bytes.Buffer
+	p := startBot(t, "crash", logTo(&log), time.Second)
+	w := testWorld()
+	w.SetController(w.Player, p)
+
+	start := *w.Player.Location
+	for range 3 {
+		sim.Step(w)
+	}
+	assert.Equal(t, start, *w.Player.Location)
+	assert.True(t, p.failed)
+	p.Close()
+	assert.Contains(t, log.String(), "exit status 3")
+}
+
+func TestBotsThatStopReadingAreKilled(t *testing.T) {
+	p := startBot(t, "deaf", log.New(io.Discard, "", 0), 20*time.Millisecond)
+	w := testWorld()
+	for range 100 {
+		p.Act(w.Observe(w.Player))
+	}
+	p.Close()
+}
+
+func TestAttach(t *testing.T) {
+	var log bytes.Buffer
+	logger := logTo(&log)
+	w := testWorld()
+	t.Setenv(botMode, "chatty")
+
+	_, err := Attach(logger, w, w.Roster(), time.Second, Commands{os.Args[0], os.Args[0], os.Args[0]})
+	assert.Error(t, err, "There are only two beings")
+
+	bots, err := Attach(logger, w, w.Roster(), time.Second, Commands{os.Args[0], os.Args[0]})
+	require.NoError(t, err)
+	sim.Step(w)
+	for being := range w.Beings {
+		_, ok := w.Controller(being)
+		assert.True(t, ok)
+		assert.Equal(t, object.South, being.Direction, "Every being should be driven by its bot")
+	}
+	for _, b := range bots {
+		b.Close()
+	}
+	assert.Contains(t, log.String(), fmt.Sprintf("at %d,%d on tick 0", w.Player.Location.X, w.Player.Location.Y), "Bots can log to stderr")
+}
+
+func logTo(buf *bytes.Buffer) *log.Logger {
+	return log.New(buf, "", 0)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"gobotworld/src/gym"
+	"gobotworld/src/world"
+	"io"
+	"log"
+	"os"
+)
+
+func panicOnError(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func main() {
+	configPath := flag.String("config", "", "generate the worlds from this config file, its seed is ignored")
+	rewardSpec := flag.String("reward", gym.DefaultRewards, "rewards and their weights, e.g. torch=1,explore=0.1,enemy=1,step=0.01")
+	maxSteps := flag.Int("max-steps", gym.DefaultMaxSteps, "steps before an episode is cut short, 0 for no limit")
+	logPath := flag.String("log", "", "file to write the world log to, discarded when empty")
+	flag.Parse()
+
+	fc := world.DefaultFileConfig()
+	if *configPath != "" {
+		var err error
+		fc, err = world.LoadConfig(*configPath)
+		panicOnError(err)
+	}
+	rewards, err := gym.ParseRewards(*rewardSpec)
+	panicOnError(err)
+
+	var out io.Writer = io.Discard
+	if *logPath != "" {
+		file, err := os.Create(*logPath)
+		panicOnError(err)
+		defer file.Close()
+		out = file
+	}
+	logger := log.New(out, "", log.LstdFlags)
+
+	env := gym.NewEnv(logger, fc, rewards, *maxSteps)
+	panicOnError(env.Serve(os.Stdin, os.Stdout))
+}
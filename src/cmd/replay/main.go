@@ -0,0 +1,91 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"gobotworld/src/replay"
+	"gobotworld/src/terminal"
+	"gobotworld/src/world"
+	"io"
+	"log"
+	"os"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+func panicOnError(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+var errQuit = errors.New("replay stopped")
+
+func main() {
+	headless := flag.Bool("headless", false, "replay without drawing the world")
+	delay := flag.Duration("delay", 50*time.Millisecond, "time between frames when drawing the world")
+	logPath := flag.String("log", "", "file to write the world log to, discarded when empty")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		panicOnError(fmt.Errorf("usage: replay [flags] <replay file>"))
+	}
+	file, err := replay.ReadFile(flag.Arg(0))
+	panicOnError(err)
+
+	var out io.Writer = io.Discard
+	if *logPath != "" {
+		logFile, err := os.Create(*logPath)
+		panicOnError(err)
+		defer logFile.Close()
+		out = logFile
+	}
+	logger := log.New(out, "", log.LstdFlags)
+
+	var frame func(w world.World)
+	fini := func() {}
+	if !*headless {
+		term, err := terminal.Init()
+		panicOnError(err)
+		term.Logger = logger
+		fini = term.Fini
+
+		quit := make(chan struct{})
+		go func() {
+			for {
+				ev := term.PollEvent()
+				switch ev := ev.(type) {
+				case *tcell.EventKey:
+					if ev.Key() == tcell.KeyEscape || ev.Key() == tcell.KeyEnter {
+						close(quit)
+						return
+					}
+				case *tcell.EventResize:
+					term.Show()
+				case nil:
+					return
+				}
+			}
+		}()
+
+		frame = func(w world.World) {
+			term.DrawWorld(w)
+			term.Show()
+			select {
+			case <-quit:
+				fini()
+				panicOnError(errQuit)
+			case <-time.After(*delay):
+			}
+		}
+	}
+
+	_, err = replay.Play(logger, file, frame)
+	// Leave the terminal before reporting the result
+	fini()
+	panicOnError(err)
+	fmt.Printf("replayed %d ticks with %d actions, state hash %s matches\n", file.Ticks, len(file.Actions), file.Hash)
+}
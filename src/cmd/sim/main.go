@@ -0,0 +1,82 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"gobotworld/src/bot"
+	"gobotworld/src/sim"
+	"gobotworld/src/vm"
+	"gobotworld/src/world"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+func panicOnError(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func main() {
+	ticks := flag.Int("ticks", 1000, "number of ticks to run each simulation for")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed of the first simulation")
+	runs := flag.Int("runs", 1, "number of simulations to run, each one uses the next seed")
+	every := flag.Int("every", 0, "print the player position every n ticks, 0 to only print the result")
+	logPath := flag.String("log", "", "file to write the world log to, discarded when empty")
+	configPath := flag.String("config", "", "generate the worlds from this config file, its seed is ignored")
+	var bots bot.Commands
+	flag.Var(&bots, "bot", "run this command as a bot, the first drives the player and any more the NPCs")
+	botTimeout := flag.Duration("bot-timeout", bot.DefaultTimeout, "how long bots have to answer each tick")
+	var programs vm.Paths
+	flag.Var(&programs, "vm", "run the bot program in this file, on the beings after those the bots drive")
+	budget := flag.Int("budget", vm.DefaultBudget, "how many instructions bot programs may run each tick")
+	flag.Parse()
+
+	fc := world.DefaultFileConfig()
+	if *configPath != "" {
+		var err error
+		fc, err = world.LoadConfig(*configPath)
+		panicOnError(err)
+	}
+
+	var out io.Writer = io.Discard
+	if *logPath != "" {
+		file, err := os.Create(*logPath)
+		panicOnError(err)
+		defer file.Close()
+		out = file
+	}
+	logger := log.New(out, "", log.LstdFlags)
+
+	for run := 0; run < *runs; run++ {
+		runSeed := *seed + int64(run)
+		fc.Seed = &runSeed
+		w, err := fc.NewWorld(logger)
+		panicOnError(err)
+		roster := w.Roster()
+		processes, err := bot.Attach(logger, w, roster, *botTimeout, bots)
+		panicOnError(err)
+		_, err = vm.Attach(logger, w, roster[len(processes):], *budget, programs)
+		panicOnError(err)
+		runner := sim.Runner{World: w}
+		if *every > 0 {
+			runner.Hooks = append(runner.Hooks, func(tick int, w world.World) error {
+				if tick%*every == 0 {
+					fmt.Printf("seed=%d tick=%d player=%v\n", runSeed, tick, *w.Player.Location)
+				}
+				return nil
+			})
+		}
+
+		start := time.Now()
+		ran, err := runner.Run(*ticks)
+		for _, p := range processes {
+			p.Close()
+		}
+		panicOnError(err)
+		fmt.Printf("seed=%d ticks=%d player=%v elapsed=%s\n", runSeed, ran, *runner.World.Player.Location, time.Since(start))
+	}
+}
@@ -0,0 +1,83 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"gobotworld/src/tournament"
+	"gobotworld/src/world"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+func panicOnError(err error) {
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "%v\n", err)
+		os.Exit(1)
+	}
+}
+
+func main() {
+	seeds := flag.String("seeds", "", "comma separated seeds to play instead of those in the tournament file")
+	outDir := flag.String("out", "", "directory to write the results table and a replay of every match to")
+	logPath := flag.String("log", "", "file to write the world log to, discarded when empty")
+	flag.Parse()
+
+	if flag.NArg() != 1 {
+		panicOnError(fmt.Errorf("usage: tournament [flags] <tournament file>"))
+	}
+	spec, err := tournament.LoadSpec(flag.Arg(0))
+	panicOnError(err)
+	if *seeds != "" {
+		spec.Seeds = nil
+		for _, s := range strings.Split(*seeds, ",") {
+			seed, err := strconv.ParseInt(strings.TrimSpace(s), 10, 64)
+			panicOnError(err)
+			spec.Seeds = append(spec.Seeds, seed)
+		}
+	}
+
+	fc := world.DefaultFileConfig()
+	if spec.Config != "" {
+		fc, err = world.LoadConfig(spec.Config)
+		panicOnError(err)
+	}
+
+	var out io.Writer = io.Discard
+	if *logPath != "" {
+		file, err := os.Create(*logPath)
+		panicOnError(err)
+		defer file.Close()
+		out = file
+	}
+	logger := log.New(out, "", log.LstdFlags)
+
+	var saveReplay func(tournament.Match) error
+	if *outDir != "" {
+		panicOnError(os.MkdirAll(*outDir, 0o755))
+		saveReplay = func(m tournament.Match) error {
+			return m.Replay.WriteFile(filepath.Join(*outDir, fmt.Sprintf("%s-%d.json", m.Entry, m.Seed)))
+		}
+	}
+
+	matches, err := tournament.Run(logger, spec, fc, func(m tournament.Match) error {
+		fmt.Fprintf(os.Stderr, "%s seed %d: %.1f\n", m.Entry, m.Seed, m.Total)
+		if saveReplay != nil {
+			return saveReplay(m)
+		}
+		return nil
+	})
+	panicOnError(err)
+
+	results := tournament.Rank(matches)
+	panicOnError(tournament.WriteTable(os.Stdout, spec, results))
+	if *outDir != "" {
+		table, err := os.Create(filepath.Join(*outDir, "results.txt"))
+		panicOnError(err)
+		defer table.Close()
+		panicOnError(tournament.WriteTable(table, spec, results))
+	}
+}
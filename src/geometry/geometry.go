@@ -45,13 +45,7 @@ func intMax(a, b int) int {
 
 func Circle(origin *image.Point, size int) Window {
 	left := origin.X - size - 1
-	if left < 0 {
-		left = 0
-	}
 	top := origin.Y - size
-	if top < 0 {
-		top = 0
-	}
 	return Window{
 		Left:   left,
 		Width:  size*2 + 1,
@@ -57,3 +57,9 @@ func TestQuickSqrt(t *testing.T) {
 	assert.Equal(t, 1, geometry.QuickSqrt(1), "Square root of 1 should be 1")
 	assert.Equal(t, 2, geometry.QuickSqrt(4), "Square root of 4 should be 2")
 }
+
+func TestCircleNearOrigin(t *testing.T) {
+	origin := image.Point{X: -2, Y: 1}
+	circle := geometry.Circle(&origin, 3)
+	assert.Equal(t, geometry.Window{Left: -6, Top: -2, Width: 7, Height: 7}, circle, "Circles should not be cut off at zero")
+}
@@ -0,0 +1,206 @@
+// Package gym wraps the world as an environment for training agents, in the
+// style of OpenAI Gym: Reset starts an episode and Step takes one action in it,
+// returning what the agent observes and the reward it earned.
+package gym
+
+import (
+	"errors"
+	"fmt"
+	"gobotworld/src/sim"
+	"gobotworld/src/world"
+	"gobotworld/src/world/object"
+	"image"
+	"log"
+)
+
+// Action is what the agent does in a step.
+type Action int
+
+const (
+	Wait Action = iota
+	North
+	South
+	East
+	West
+
+	// Actions is how many actions there are.
+	Actions = 5
+)
+
+var actionDirections = map[Action]object.Direction{
+	North: object.North,
+	South: object.South,
+	East:  object.East,
+	West:  object.West,
+}
+
+// The codes each cell of an observation's grid can hold.
+const (
+	OffMap = iota
+	Floor
+	Wall
+	Torch
+	Enemy
+	Critter
+	Self
+	// Unseen cells are hidden from the agent by walls.
+	Unseen
+)
+
+// Codes names the cell codes, for the spec of the environment.
+var Codes = map[string]int{
+	"offMap":  OffMap,
+	"floor":   Floor,
+	"wall":    Wall,
+	"torch":   Torch,
+	"enemy":   Enemy,
+	"critter": Critter,
+	"self":    Self,
+	"unseen":  Unseen,
+}
+
+// Side is the width and height of an observation's grid.
+const Side = world.ObservationRadius*2 + 1
+
+// Observation is what the agent sees. Grid is Side by Side cells, row by row,
+// centred on the agent with north at the top.
+type Observation struct {
+	Grid  []int `json:"grid"`
+	Light int   `json:"light"`
+	Day   bool  `json:"day"`
+	Tick  int   `json:"tick"`
+}
+
+// Info is what the agent isn't meant to see but a trainer may want to log.
+type Info struct {
+	Position image.Point `json:"position"`
+	Moved    bool        `json:"moved"`
+	Steps    int         `json:"steps"`
+}
+
+// StepResult is everything a step returns. Done is set when the reward ended
+// the episode and Truncated when it ran out of steps.
+type StepResult struct {
+	Observation Observation `json:"observation"`
+	Reward      float64     `json:"reward"`
+	Done        bool        `json:"done"`
+	Truncated   bool        `json:"truncated"`
+	Info        Info        `json:"info"`
+}
+
+// DefaultMaxSteps is how long an episode lasts unless the reward ends it.
+const DefaultMaxSteps = 500
+
+var (
+	ErrNotReset = errors.New("the environment has to be reset first")
+	ErrOver     = errors.New("the episode is over, reset the environment")
+)
+
+// Env is an environment where the agent plays the player of a world made from
+// a config. NPCs carry on as they always do.
+type Env struct {
+	Config   world.FileConfig
+	Reward   Reward
+	MaxSteps int
+
+	logger *log.Logger
+	world  *world.World
+	steps  int
+	over   bool
+}
+
+func NewEnv(logger *log.Logger, fc world.FileConfig, reward Reward, maxSteps int) *Env {
+	return &Env{Config: fc, Reward: reward, MaxSteps: maxSteps, logger: logger}
+}
+
+// Reset starts a new episode on the world the seed generates.
+func (e *Env) Reset(seed int64) (Observation, error) {
+	fc := e.Config
+	fc.Seed = &seed
+	w, err := fc.NewWorld(e.logger)
+	if err != nil {
+		return Observation{}, err
+	}
+	e.world, e.steps, e.over = &w, 0, false
+	e.Reward.Reset(w)
+	return encode(w.Observe(w.Player)), nil
+}
+
+// Step takes the action and steps the world on.
+func (e *Env) Step(a Action) (StepResult, error) {
+	if e.world == nil {
+		return StepResult{}, ErrNotReset
+	}
+	if e.over {
+		return StepResult{}, ErrOver
+	}
+	if a < 0 || a >= Actions {
+		return StepResult{}, fmt.Errorf("unknown action %d", a)
+	}
+
+	w := *e.world
+	start := *w.Player.Location
+	if direction, ok := actionDirections[a]; ok {
+		w.Enqueue(world.MoveAction(w.Player, direction))
+	}
+	sim.Step(w)
+	e.steps++
+
+	moved := *w.Player.Location != start
+	reward, done := e.Reward.Score(w, !moved && a != Wait)
+	truncated := !done && e.MaxSteps > 0 && e.steps >= e.MaxSteps
+	e.over = done || truncated
+	return StepResult{
+		Observation: encode(w.Observe(w.Player)),
+		Reward:      reward,
+		Done:        done,
+		Truncated:   truncated,
+		Info:        Info{Position: *w.Player.Location, Moved: moved, Steps: e.steps},
+	}, nil
+}
+
+// World is the world of the current episode, false before the first Reset.
+func (e *Env) World() (world.World, bool) {
+	if e.world == nil {
+		return world.World{}, false
+	}
+	return *e.world, true
+}
+
+func encode(obs world.Observation) Observation {
+	out := Observation{
+		Grid:  make([]int, 0, len(obs.Cells)),
+		Light: obs.Light,
+		Day:   obs.Cycle == object.DayTime,
+		Tick:  obs.Tick,
+	}
+	for _, cell := range obs.Cells {
+		out.Grid = append(out.Grid, code(cell, obs.Location))
+	}
+	return out
+}
+
+func code(cell world.Cell, self image.Point) int {
+	if !cell.Visible {
+		return Unseen
+	}
+	if len(cell.Things) == 0 {
+		return OffMap
+	}
+	switch cell.Things[len(cell.Things)-1] {
+	case object.PlayerType:
+		if cell.Point == self {
+			return Self
+		}
+	case object.EnemyType:
+		return Enemy
+	case object.CritterType:
+		return Critter
+	case object.TorchType:
+		return Torch
+	}
+	if cell.Passable {
+		return Floor
+	}
+	return Wall
+}
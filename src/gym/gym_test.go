@@ -0,0 +1,151 @@
+package gym
+
+import (
+	"gobotworld/src/world"
+	"gobotworld/src/world/object"
+	"image"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func testConfig() world.FileConfig {
+	fc := world.DefaultFileConfig()
+	fc.Width, fc.Height = 40, 40
+	fc.NPCs = nil
+	return fc
+}
+
+func testEnv(reward Reward, maxSteps int) *Env {
+	return NewEnv(log.New(io.Discard, "", 0), testConfig(), reward, maxSteps)
+}
+
+func TestReset(t *testing.T) {
+	env := testEnv(Rewards{}, 0)
+	_, err := env.Step(Wait)
+	assert.ErrorIs(t, err, ErrNotReset)
+
+	obs, err := env.Reset(1)
+	require.NoError(t, err)
+	assert.Len(t, obs.Grid, Side*Side)
+	assert.Equal(t, Self, obs.Grid[len(obs.Grid)/2], "The agent is at the centre")
+	assert.True(t, obs.Day)
+
+	w, ok := env.World()
+	require.True(t, ok)
+	again, err := env.Reset(1)
+	require.NoError(t, err)
+	assert.Equal(t, obs, again, "The same seed gives the same episode")
+	w2, _ := env.World()
+	assert.NotSame(t, w.Player, w2.Player, "Every reset makes a new world")
+}
+
+// drawnConfig is a config for the map drawn in rows.
+func drawnConfig(t *testing.T, rows ...string) world.FileConfig {
+	path := filepath.Join(t.TempDir(), "map.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(rows, "\n")), 0o644))
+	fc := testConfig()
+	fc.Width, fc.Height = 0, 0
+	fc.Generator = &world.GeneratorConfig{Type: "ascii", Path: path}
+	return fc
+}
+
+func TestStep(t *testing.T) {
+	env := NewEnv(log.New(io.Discard, "", 0), drawnConfig(t,
+		"@@@@@",
+		"@ M @",
+		"@   @",
+		"@@@@@",
+	), Rewards{}, 3)
+	_, err := env.Reset(1)
+	require.NoError(t, err)
+
+	result, err := env.Step(South)
+	require.NoError(t, err)
+	assert.True(t, result.Info.Moved)
+	assert.Equal(t, image.Point{X: 2, Y: 2}, result.Info.Position)
+	assert.Equal(t, 1, result.Observation.Tick)
+	assert.Equal(t, 1, result.Info.Steps)
+
+	_, err = env.Step(Actions)
+	assert.Error(t, err, "Only known actions can be taken")
+
+	env.Step(Wait)
+	result, _ = env.Step(Wait)
+	assert.True(t, result.Truncated, "Episodes are cut short after MaxSteps")
+	assert.False(t, result.Done)
+	_, err = env.Step(Wait)
+	assert.ErrorIs(t, err, ErrOver)
+}
+
+func TestStepIntoWall(t *testing.T) {
+	env := NewEnv(log.New(io.Discard, "", 0), drawnConfig(t,
+		"@@@@@",
+		"@ M @",
+		"@   @",
+		"@@@@@",
+	), Rewards{}, 0)
+	_, err := env.Reset(1)
+	require.NoError(t, err)
+
+	result, err := env.Step(North)
+	require.NoError(t, err)
+	assert.False(t, result.Info.Moved, "Walls can't be walked into")
+	assert.Equal(t, image.Point{X: 2, Y: 1}, result.Info.Position)
+	assert.Equal(t, 1, result.Info.Steps, "Bumping into a wall still takes a step")
+}
+
+// endAfter ends the episode after its number of steps.
+type endAfter struct{ steps, taken int }
+
+func (e *endAfter) Reset(world.World) { e.taken = 0 }
+
+func (e *endAfter) Score(world.World, bool) (float64, bool) {
+	e.taken++
+	return 0.5, e.taken == e.steps
+}
+
+func TestRewardEndsTheEpisode(t *testing.T) {
+	env := testEnv(&endAfter{steps: 2}, 10)
+	_, err := env.Reset(1)
+	require.NoError(t, err)
+
+	result, _ := env.Step(Wait)
+	assert.Equal(t, 0.5, result.Reward)
+	assert.False(t, result.Done)
+	result, _ = env.Step(Wait)
+	assert.True(t, result.Done)
+	assert.False(t, result.Truncated)
+	_, err = env.Step(Wait)
+	assert.ErrorIs(t, err, ErrOver)
+
+	_, err = env.Reset(2)
+	require.NoError(t, err)
+	_, err = env.Step(Wait)
+	assert.NoError(t, err, "Reset starts a new episode")
+}
+
+func TestEncode(t *testing.T) {
+	self := image.Point{X: 5, Y: 5}
+	for _, tc := range []struct {
+		cell world.Cell
+		code int
+	}{
+		{world.Cell{Visible: true}, OffMap},
+		{world.Cell{}, Unseen},
+		{world.Cell{Visible: true, Things: []object.ObjectType{object.Dirt2Type}, Passable: true}, Floor},
+		{world.Cell{Visible: true, Things: []object.ObjectType{object.ObstacleType}}, Wall},
+		{world.Cell{Visible: true, Things: []object.ObjectType{object.TorchType}}, Torch},
+		{world.Cell{Visible: true, Things: []object.ObjectType{object.Dirt1Type, object.EnemyType}}, Enemy},
+		{world.Cell{Visible: true, Things: []object.ObjectType{object.Dirt1Type, object.CritterType}}, Critter},
+		{world.Cell{Visible: true, Point: self, Things: []object.ObjectType{object.Dirt1Type, object.PlayerType}}, Self},
+	} {
+		assert.Equal(t, tc.code, code(tc.cell, self), "%v", tc.cell.Things)
+	}
+}
@@ -0,0 +1,145 @@
+package gym
+
+import (
+	"fmt"
+	"gobotworld/src/world"
+	"gobotworld/src/world/object"
+	"image"
+	"strconv"
+	"strings"
+)
+
+// Reward scores each step of an episode. Reset is called with the new world
+// at the start of every episode, Score after every step with whether the
+// player bumped into something trying to move. Score also says whether the
+// episode is over.
+type Reward interface {
+	Reset(w world.World)
+	Score(w world.World, bumped bool) (float64, bool)
+}
+
+// ReachTorch scores 1 and ends the episode once the player is next to a light.
+type ReachTorch struct{}
+
+func (ReachTorch) Reset(world.World) {}
+
+func (ReachTorch) Score(w world.World, _ bool) (float64, bool) {
+	player := *w.Player.Location
+	for _, light := range w.LightSources() {
+		if manhattan(player, *light.Location) <= 1 {
+			return 1, true
+		}
+	}
+	return 0, false
+}
+
+// Explore scores 1 for every cell the player stands on for the first time.
+type Explore struct {
+	visited map[image.Point]bool
+}
+
+func (e *Explore) Reset(w world.World) {
+	e.visited = map[image.Point]bool{*w.Player.Location: true}
+}
+
+func (e *Explore) Score(w world.World, _ bool) (float64, bool) {
+	p := *w.Player.Location
+	if e.visited[p] {
+		return 0, false
+	}
+	e.visited[p] = true
+	return 1, false
+}
+
+// AvoidEnemy scores -1 and ends the episode when an enemy is next to the player.
+type AvoidEnemy struct{}
+
+func (AvoidEnemy) Reset(world.World) {}
+
+func (AvoidEnemy) Score(w world.World, _ bool) (float64, bool) {
+	player := *w.Player.Location
+	for being := range w.Beings {
+		if being.Ident().Type == object.EnemyType && manhattan(player, *being.Location) <= 1 {
+			return -1, true
+		}
+	}
+	return 0, false
+}
+
+// StepCost scores -1 for every step, and another -1 for bumping into things,
+// to get episodes done quickly.
+type StepCost struct{}
+
+func (StepCost) Reset(world.World) {}
+
+func (StepCost) Score(_ world.World, bumped bool) (float64, bool) {
+	if bumped {
+		return -2, false
+	}
+	return -1, false
+}
+
+// Weighted is a reward and how much it counts for.
+type Weighted struct {
+	Name   string
+	Reward Reward
+	Weight float64
+}
+
+// Rewards adds up its rewards, each times its weight. The episode is over as
+// soon as any of them says so.
+type Rewards []Weighted
+
+func (rs Rewards) Reset(w world.World) {
+	for _, r := range rs {
+		r.Reward.Reset(w)
+	}
+}
+
+func (rs Rewards) Score(w world.World, bumped bool) (float64, bool) {
+	total, done := 0.0, false
+	for _, r := range rs {
+		score, over := r.Reward.Score(w, bumped)
+		total += score * r.Weight
+		done = done || over
+	}
+	return total, done
+}
+
+// rewards are the rewards ParseRewards knows by name.
+var rewards = map[string]func() Reward{
+	"torch":   func() Reward { return ReachTorch{} },
+	"explore": func() Reward { return &Explore{} },
+	"enemy":   func() Reward { return AvoidEnemy{} },
+	"step":    func() Reward { return StepCost{} },
+}
+
+// DefaultRewards is what ParseRewards is usually given.
+const DefaultRewards = "torch=1,step=0.01"
+
+// ParseRewards reads a comma separated list of rewards by name, each with an
+// optional weight, e.g. "torch=1,explore=0.1,enemy".
+func ParseRewards(spec string) (Rewards, error) {
+	var rs Rewards
+	for _, part := range strings.Split(spec, ",") {
+		name, weight, hasWeight := strings.Cut(strings.TrimSpace(part), "=")
+		newReward, ok := rewards[name]
+		if !ok {
+			return nil, fmt.Errorf("unknown reward %q", name)
+		}
+		w := 1.0
+		if hasWeight {
+			var err error
+			if w, err = strconv.ParseFloat(weight, 64); err != nil {
+				return nil, fmt.Errorf("weight of reward %s: %w", name, err)
+			}
+		}
+		rs = append(rs, Weighted{Name: name, Reward: newReward(), Weight: w})
+	}
+	return rs, nil
+}
+
+func manhattan(a, b image.Point) int {
+	d := a.Sub(b)
+	return max(d.X, -d.X) + max(d.Y, -d.Y)
+}
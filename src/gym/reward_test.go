@@ -0,0 +1,93 @@
+package gym
+
+import (
+	"gobotworld/src/world"
+	"image"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// corridor is a world with the player at the west end of a corridor, a torch
+// in the wall at the east end and an enemy round the corner.
+func corridor(t *testing.T) world.World {
+	am, err := world.ReadASCIIMap(strings.NewReader(strings.Join([]string{
+		"@@@@@@^@",
+		"@M     @",
+		"@@@@@@ @",
+		"@@@@@E @",
+		"@@@@@@@@",
+	}, "\n")))
+	require.NoError(t, err)
+	return am.NewWorld(log.New(io.Discard, "", 0), world.NewConfig().WithNPCs().WithSeed(1))
+}
+
+func TestReachTorch(t *testing.T) {
+	w := corridor(t)
+	score, done := ReachTorch{}.Score(w, false)
+	assert.Zero(t, score)
+	assert.False(t, done)
+
+	w.Relocate(w.Player, image.Point{X: 6, Y: 1})
+	score, done = ReachTorch{}.Score(w, false)
+	assert.Equal(t, 1.0, score)
+	assert.True(t, done)
+}
+
+func TestExplore(t *testing.T) {
+	w := corridor(t)
+	e := &Explore{}
+	e.Reset(w)
+	score, _ := e.Score(w, false)
+	assert.Zero(t, score, "The start has been seen")
+
+	w.Relocate(w.Player, image.Point{X: 2, Y: 1})
+	score, _ = e.Score(w, false)
+	assert.Equal(t, 1.0, score)
+	score, _ = e.Score(w, false)
+	assert.Zero(t, score, "Cells only count once")
+
+	e.Reset(w)
+	w.Relocate(w.Player, image.Point{X: 1, Y: 1})
+	score, _ = e.Score(w, false)
+	assert.Equal(t, 1.0, score, "Reset forgets where the player has been")
+}
+
+func TestAvoidEnemy(t *testing.T) {
+	w := corridor(t)
+	score, done := AvoidEnemy{}.Score(w, false)
+	assert.Zero(t, score)
+	assert.False(t, done)
+
+	w.Relocate(w.Player, image.Point{X: 6, Y: 3})
+	score, done = AvoidEnemy{}.Score(w, false)
+	assert.Equal(t, -1.0, score)
+	assert.True(t, done)
+}
+
+func TestRewards(t *testing.T) {
+	rs, err := ParseRewards("torch=2, step=0.5,enemy")
+	require.NoError(t, err)
+	require.Len(t, rs, 3)
+	assert.Equal(t, Weighted{Name: "enemy", Reward: AvoidEnemy{}, Weight: 1}, rs[2])
+
+	w := corridor(t)
+	rs.Reset(w)
+	score, done := rs.Score(w, true)
+	assert.Equal(t, -1.0, score, "Bumping into things costs double")
+	assert.False(t, done)
+
+	w.Relocate(w.Player, image.Point{X: 6, Y: 1})
+	score, done = rs.Score(w, false)
+	assert.Equal(t, 1.5, score)
+	assert.True(t, done)
+
+	_, err = ParseRewards("torch,gold")
+	assert.EqualError(t, err, `unknown reward "gold"`)
+	_, err = ParseRewards("torch=lots")
+	assert.Error(t, err)
+}
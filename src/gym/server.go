@@ -0,0 +1,86 @@
+package gym
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Request is a line sent to Serve. Command is "spec", "reset", "step" or
+// "close". Reset takes the seed and step the action.
+type Request struct {
+	Command string `json:"command"`
+	Seed    int64  `json:"seed,omitempty"`
+	Action  Action `json:"action,omitempty"`
+}
+
+// Spec describes the environment so trainers can size their models.
+type Spec struct {
+	Actions  int            `json:"actions"`
+	Side     int            `json:"side"`
+	Codes    map[string]int `json:"codes"`
+	MaxSteps int            `json:"maxSteps"`
+	Rewards  []string       `json:"rewards,omitempty"`
+}
+
+// Response is the line Serve answers a request with. Only the part for the
+// request is set, or Error if it failed.
+type Response struct {
+	Spec        *Spec        `json:"spec,omitempty"`
+	Observation *Observation `json:"observation,omitempty"`
+	Step        *StepResult  `json:"step,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+func (e *Env) spec() *Spec {
+	s := &Spec{Actions: Actions, Side: Side, Codes: Codes, MaxSteps: e.MaxSteps}
+	if rs, ok := e.Reward.(Rewards); ok {
+		for _, r := range rs {
+			s.Rewards = append(s.Rewards, fmt.Sprintf("%s=%g", r.Name, r.Weight))
+		}
+	}
+	return s
+}
+
+// Serve answers requests read from r, one JSON line each, with a JSON line
+// written to w, until it is sent "close" or r ends. Bad requests are answered
+// with an error and the environment carries on.
+func (e *Env) Serve(r io.Reader, w io.Writer) error {
+	scanner := bufio.NewScanner(r)
+	out := json.NewEncoder(w)
+	for scanner.Scan() {
+		var req Request
+		var resp Response
+		if err := json.Unmarshal(scanner.Bytes(), &req); err != nil {
+			resp.Error = fmt.Sprintf("reading request: %v", err)
+		} else {
+			switch req.Command {
+			case "spec":
+				resp.Spec = e.spec()
+			case "reset":
+				obs, err := e.Reset(req.Seed)
+				if err != nil {
+					resp.Error = err.Error()
+				} else {
+					resp.Observation = &obs
+				}
+			case "step":
+				result, err := e.Step(req.Action)
+				if err != nil {
+					resp.Error = err.Error()
+				} else {
+					resp.Step = &result
+				}
+			case "close":
+				return nil
+			default:
+				resp.Error = fmt.Sprintf("unknown command %q", req.Command)
+			}
+		}
+		if err := out.Encode(resp); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
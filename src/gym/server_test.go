@@ -0,0 +1,49 @@
+package gym
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestServe(t *testing.T) {
+	rewards, err := ParseRewards("explore=1")
+	require.NoError(t, err)
+	env := testEnv(rewards, 5)
+
+	requests := strings.Join([]string{
+		`{"command":"spec"}`,
+		`{"command":"step","action":1}`,
+		`{"command":"reset","seed":1}`,
+		`{"command":"step","action":3}`,
+		`not json`,
+		`{"command":"dance"}`,
+		`{"command":"close"}`,
+		`{"command":"spec"}`,
+	}, "\n")
+	var out bytes.Buffer
+	require.NoError(t, env.Serve(strings.NewReader(requests), &out))
+
+	var responses []Response
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var r Response
+		require.NoError(t, decoder.Decode(&r))
+		responses = append(responses, r)
+	}
+	require.Len(t, responses, 6, "Nothing is answered after close")
+
+	require.NotNil(t, responses[0].Spec)
+	assert.Equal(t, Spec{Actions: Actions, Side: Side, Codes: Codes, MaxSteps: 5, Rewards: []string{"explore=1"}}, *responses[0].Spec)
+	assert.Equal(t, ErrNotReset.Error(), responses[1].Error)
+	require.NotNil(t, responses[2].Observation)
+	assert.Len(t, responses[2].Observation.Grid, Side*Side)
+	require.NotNil(t, responses[3].Step)
+	assert.Equal(t, 1, responses[3].Step.Info.Steps)
+	assert.Contains(t, responses[4].Error, "reading request")
+	assert.Equal(t, `unknown command "dance"`, responses[5].Error)
+}
@@ -1,10 +1,14 @@
 package main
 
 import (
+	"flag"
 	"fmt"
+	"gobotworld/src/bot"
+	"gobotworld/src/replay"
+	"gobotworld/src/sim"
 	"gobotworld/src/terminal"
+	"gobotworld/src/vm"
 	"gobotworld/src/world"
-	"gobotworld/src/world/object"
 	"log"
 	"os"
 	"time"
@@ -20,6 +24,25 @@ func panicOnError(err error) {
 }
 
 func main() {
+	loadPath := flag.String("load", "", "load the world from a save file instead of generating one")
+	savePath := flag.String("save", "", "save the world to this file when the game exits")
+	recordPath := flag.String("record", "", "record the seed and every move to this replay file")
+	configPath := flag.String("config", "", "generate the world from this config file")
+	mapPath := flag.String("map", "", "play on a map drawn in this text file")
+	debug := flag.Bool("debug", false, "show what each NPC is doing")
+	var bots bot.Commands
+	flag.Var(&bots, "bot", "run this command as a bot, the first drives the player and any more the NPCs")
+	botTimeout := flag.Duration("bot-timeout", bot.DefaultTimeout, "how long bots have to answer each tick")
+	var programs vm.Paths
+	flag.Var(&programs, "vm", "run the bot program in this file, on the beings after those the bots drive")
+	budget := flag.Int("budget", vm.DefaultBudget, "how many instructions bot programs may run each tick")
+	seed := flag.Int64("seed", time.Now().UnixNano(), "seed used to generate the world, unless the config sets one")
+	flag.Parse()
+
+	if *loadPath != "" && *recordPath != "" {
+		panicOnError(fmt.Errorf("a loaded world cannot be recorded, replays start from a seed"))
+	}
+
 	// Create a file
 	file, err := os.Create("game.log")
 	if err != nil {
@@ -28,10 +51,48 @@ func main() {
 	defer file.Close()
 	logger := log.New(file, "", log.LstdFlags)
 
-	gameWorld := world.DefaultWorld(logger)
+	fc := world.DefaultFileConfig()
+	if *configPath != "" {
+		fc, err = world.LoadConfig(*configPath)
+		panicOnError(err)
+	}
+	if *mapPath != "" {
+		if *configPath == "" {
+			// The default NPC spawn may be off a small map, use the ones drawn on it
+			fc.NPCs = nil
+		}
+		fc.Generator = &world.GeneratorConfig{Type: "ascii", Path: *mapPath}
+	}
+	if fc.Seed == nil {
+		fc.Seed = seed
+	}
+
+	var gameWorld world.World
+	if *loadPath != "" {
+		gameWorld, err = world.LoadFile(logger, *loadPath)
+	} else {
+		logger.Printf("Generating world with seed %d", *fc.Seed)
+		gameWorld, err = fc.NewWorld(logger)
+	}
+	panicOnError(err)
+
+	var recorder *replay.Recorder
+	if *recordPath != "" {
+		recorder = replay.NewRecorder(gameWorld)
+	}
+
+	// The keyboard drives the player unless a bot does
+	keyboard := &terminal.Keyboard{}
+	gameWorld.SetController(gameWorld.Player, keyboard)
+	roster := gameWorld.Roster()
+	processes, err := bot.Attach(logger, gameWorld, roster, *botTimeout, bots)
+	panicOnError(err)
+	_, err = vm.Attach(logger, gameWorld, roster[len(processes):], *budget, programs)
+	panicOnError(err)
 
 	term, err := terminal.Init()
 	term.Logger = logger
+	term.Debug = *debug
 	defer term.Fini()
 
 	panicOnError(err)
@@ -46,15 +107,8 @@ func main() {
 				case tcell.KeyEscape, tcell.KeyEnter:
 					close(quit)
 					return
-				case tcell.KeyLeft:
-					gameWorld.Move(gameWorld.Player, object.West)
-				case tcell.KeyRight:
-					gameWorld.Move(gameWorld.Player, object.East)
-				case tcell.KeyUp:
-					gameWorld.Move(gameWorld.Player, object.North)
-				case tcell.KeyDown:
-					gameWorld.Move(gameWorld.Player, object.South)
-
+				default:
+					keyboard.HandleKey(ev)
 				}
 			case *tcell.EventResize:
 				term.Show()
@@ -73,11 +127,23 @@ loop:
 		case <-time.After(time.Millisecond * 50): // TODO: Add back subtracting `dur` to make it snappier
 		}
 		start := time.Now()
-		gameWorld.Tick()
-		gameWorld.NpcMove()
+		sim.Step(gameWorld)
 		term.DrawWorld(gameWorld)
 		term.Show()
 		cnt++
 		dur += time.Since(start)
 	}
+
+	term.Fini()
+	for _, p := range processes {
+		p.Close()
+	}
+	if recorder != nil {
+		recording := recorder.Finish()
+		recording.Config = &fc
+		panicOnError(recording.WriteFile(*recordPath))
+	}
+	if *savePath != "" {
+		panicOnError(gameWorld.SaveFile(*savePath))
+	}
 }
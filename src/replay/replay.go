@@ -0,0 +1,187 @@
+// Package replay records the player's actions in a world and plays them back
+// so that a run can be reproduced exactly, e.g. to chase a movement bug.
+package replay
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gobotworld/src/sim"
+	"gobotworld/src/world"
+	"gobotworld/src/world/object"
+	"io"
+	"log"
+	"os"
+	"sync"
+)
+
+// Version is written into every replay file. Read refuses files written with a
+// version it does not know how to play. Version 1 files only hold the actions
+// of the player.
+const Version = 2
+
+// ErrHashMismatch is returned by Play when the replayed world does not end up
+// in the state that was recorded.
+var ErrHashMismatch = errors.New("replay ended in a different state")
+
+// Entry is a single action and the tick it was applied on. Being is the index
+// of the being that took it, or 0 for the player.
+type Entry struct {
+	Tick      int              `json:"tick"`
+	Being     int              `json:"being,omitempty"`
+	Kind      world.ActionKind `json:"kind"`
+	Direction object.Direction `json:"direction,omitempty"`
+}
+
+// File is a recording. When Config is nil the world was made by
+// world.SeededWorld, otherwise by the config with its seed set to Seed.
+type File struct {
+	Version int               `json:"version"`
+	Seed    int64             `json:"seed"`
+	Config  *world.FileConfig `json:"config,omitempty"`
+	Ticks   int               `json:"ticks"`
+	Hash    string            `json:"hash"`
+	Actions []Entry           `json:"actions"`
+	// Controlled are the indexes of the NPCs that were driven by controllers
+	// rather than their behaviour, such as bots.
+	Controlled []int `json:"controlled,omitempty"`
+}
+
+// Recorder captures every action the player, or any being with a controller,
+// takes in a world. The world must have been created with world.SeededWorld,
+// or from a config file that is then stored in File.Config, for the recording
+// to play back.
+type Recorder struct {
+	mu    sync.Mutex
+	world world.World
+	file  File
+}
+
+func NewRecorder(w world.World) *Recorder {
+	r := &Recorder{world: w, file: File{Version: Version, Seed: w.Seed()}}
+	w.OnAction(r.record)
+	return r
+}
+
+func (r *Recorder) record(tick int, a world.Action) {
+	entry := Entry{Tick: tick, Kind: a.Kind, Direction: a.Direction}
+	if a.Being != r.world.Player {
+		entry.Being = a.Being.Ident().Index
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.file.Actions = append(r.file.Actions, entry)
+}
+
+// Finish stamps the recording with the current tick and state hash. It must be
+// called from the goroutine that ticks the world.
+func (r *Recorder) Finish() File {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.file.Ticks = *r.world.Time
+	r.file.Hash = r.world.Hash()
+	r.file.Controlled = nil
+	for _, being := range r.world.Roster() {
+		if _, ok := r.world.Controller(being); ok && being != r.world.Player {
+			r.file.Controlled = append(r.file.Controlled, being.Ident().Index)
+		}
+	}
+	return r.file
+}
+
+func (f File) Write(w io.Writer) error {
+	return json.NewEncoder(w).Encode(f)
+}
+
+func (f File) WriteFile(path string) error {
+	out, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := f.Write(out); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
+
+func Read(r io.Reader) (File, error) {
+	var f File
+	if err := json.NewDecoder(r).Decode(&f); err != nil {
+		return File{}, fmt.Errorf("reading replay: %w", err)
+	}
+	if f.Version != Version && f.Version != 1 {
+		return File{}, fmt.Errorf("unsupported replay version %d, expected %d", f.Version, Version)
+	}
+	return f, nil
+}
+
+func ReadFile(path string) (File, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return File{}, err
+	}
+	defer in.Close()
+	return Read(in)
+}
+
+// Play rebuilds the recorded world from its seed and drives it tick by tick
+// with the recorded actions. If frame is not nil it is called after every tick,
+// which is where a renderer can draw the world. The world is returned along
+// with ErrHashMismatch if it did not end in the recorded state.
+func Play(logger *log.Logger, f File, frame func(w world.World)) (world.World, error) {
+	w, err := f.newWorld(logger)
+	if err != nil {
+		return w, err
+	}
+
+	beings := map[int]*object.Character{}
+	for being := range w.Beings {
+		beings[being.Ident().Index] = being
+	}
+	// The recorded actions drive the beings that had controllers, they mustn't
+	// follow their behaviour as well
+	idle := world.ControllerFunc(func(world.Observation) world.Action { return world.WaitAction(nil) })
+	for _, index := range f.Controlled {
+		being, ok := beings[index]
+		if !ok {
+			return w, fmt.Errorf("there is no being %d to control", index)
+		}
+		w.SetController(being, idle)
+	}
+
+	next := 0
+	for *w.Time < f.Ticks {
+		for ; next < len(f.Actions) && f.Actions[next].Tick <= *w.Time; next++ {
+			entry := f.Actions[next]
+			if entry.Tick < *w.Time {
+				return w, fmt.Errorf("action %d is for tick %d which has already passed", next, entry.Tick)
+			}
+			being := w.Player
+			if entry.Being != 0 {
+				if being = beings[entry.Being]; being == nil {
+					return w, fmt.Errorf("action %d is for being %d which doesn't exist", next, entry.Being)
+				}
+			}
+			w.Enqueue(world.Action{Being: being, Kind: entry.Kind, Direction: entry.Direction})
+		}
+		sim.Step(w)
+		if frame != nil {
+			frame(w)
+		}
+	}
+
+	if hash := w.Hash(); hash != f.Hash {
+		return w, fmt.Errorf("%w: expected %s, got %s", ErrHashMismatch, f.Hash, hash)
+	}
+	return w, nil
+}
+
+func (f File) newWorld(logger *log.Logger) (world.World, error) {
+	if f.Config == nil {
+		return world.SeededWorld(logger, f.Seed), nil
+	}
+	fc := *f.Config
+	fc.Seed = &f.Seed
+	return fc.NewWorld(logger)
+}
@@ -0,0 +1,130 @@
+package replay_test
+
+import (
+	"bytes"
+	"gobotworld/src/replay"
+	"gobotworld/src/sim"
+	"gobotworld/src/world"
+	"gobotworld/src/world/object"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func record(t *testing.T, seed int64, ticks int) replay.File {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	w := world.SeededWorld(logger, seed)
+	recorder := replay.NewRecorder(w)
+
+	moves := []object.Direction{object.East, object.East, object.South, object.West, object.North}
+	runner := sim.Runner{World: w, Hooks: []sim.Hook{
+		func(tick int, w world.World) error {
+			if tick%3 == 0 {
+				w.Enqueue(world.MoveAction(w.Player, moves[tick%len(moves)]))
+			}
+			return nil
+		},
+	}}
+	_, err := runner.Run(ticks)
+	require.NoError(t, err)
+
+	return recorder.Finish()
+}
+
+func TestRecordAndPlay(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	file := record(t, 21, 120)
+
+	assert.Equal(t, int64(21), file.Seed, "Recording should keep the seed")
+	assert.Equal(t, 120, file.Ticks, "Recording should know how long it ran")
+	assert.Len(t, file.Actions, 39, "Every player action should be recorded")
+
+	frames := 0
+	_, err := replay.Play(logger, file, func(world.World) { frames++ })
+	assert.NoError(t, err, "Replay should end in the recorded state")
+	assert.Equal(t, 120, frames, "Replay should call frame after every tick")
+}
+
+func TestPlayDetectsDivergence(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	file := record(t, 21, 120)
+	file.Actions = nil
+
+	_, err := replay.Play(logger, file, nil)
+	assert.ErrorIs(t, err, replay.ErrHashMismatch, "Replay without the player input should not match")
+}
+
+func TestWriteRead(t *testing.T) {
+	file := record(t, 5, 30)
+
+	var buf bytes.Buffer
+	require.NoError(t, file.Write(&buf))
+	read, err := replay.Read(&buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, file, read, "Replay file should survive a round trip")
+}
+
+func TestReadRejectsUnknownVersion(t *testing.T) {
+	_, err := replay.Read(bytes.NewBufferString(`{"version": 99}`))
+	assert.ErrorContains(t, err, "unsupported replay version")
+}
+
+func TestPlayWithConfig(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	fc := world.DefaultFileConfig()
+	fc.Width, fc.Height = 40, 30
+	fc.NPCs = []world.NPCConfig{{Type: "enemy", Count: 3}}
+	seed := int64(8)
+	fc.Seed = &seed
+
+	w, err := fc.NewWorld(logger)
+	require.NoError(t, err)
+	recorder := replay.NewRecorder(w)
+	_, err = sim.Runner{World: w, Hooks: []sim.Hook{
+		func(tick int, w world.World) error {
+			w.Enqueue(world.MoveAction(w.Player, object.Direction(tick%4+1)))
+			return nil
+		},
+	}}.Run(60)
+	require.NoError(t, err)
+
+	file := recorder.Finish()
+	file.Config = &fc
+
+	_, err = replay.Play(logger, file, nil)
+	assert.NoError(t, err, "Replay should rebuild the world from the stored config")
+}
+
+func TestPlayWithControlledNPCs(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	w := world.SeededWorld(logger, 3)
+	recorder := replay.NewRecorder(w)
+	roster := w.Roster()
+	require.Greater(t, len(roster), 1)
+	npc := roster[1]
+	w.SetController(npc, world.NewRandomWalk(7))
+
+	_, err := sim.Runner{World: w}.Run(50)
+	require.NoError(t, err)
+	file := recorder.Finish()
+	assert.Equal(t, []int{npc.Ident().Index}, file.Controlled)
+	require.NotEmpty(t, file.Actions)
+	assert.Equal(t, npc.Ident().Index, file.Actions[0].Being, "Actions of controlled NPCs should be recorded")
+
+	_, err = replay.Play(logger, file, nil)
+	assert.NoError(t, err, "Controlled NPCs should replay their recorded actions")
+
+	file.Controlled = nil
+	_, err = replay.Play(logger, file, nil)
+	assert.ErrorIs(t, err, replay.ErrHashMismatch, "NPCs left to their behaviour would move differently")
+}
+
+func TestReadVersionOne(t *testing.T) {
+	f, err := replay.Read(bytes.NewBufferString(`{"version": 1, "seed": 4}`))
+	require.NoError(t, err)
+	assert.Equal(t, int64(4), f.Seed)
+}
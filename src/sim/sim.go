@@ -0,0 +1,46 @@
+// Package sim steps a world without a terminal so simulations can run as fast
+// as the machine allows, e.g. in CI or batch experiments.
+package sim
+
+import (
+	"errors"
+	"gobotworld/src/world"
+)
+
+// ErrStop can be returned by a Hook to end a run early without it being
+// reported as a failure.
+var ErrStop = errors.New("stop simulation")
+
+// Hook is called after every step with the number of the step that just ran
+// (starting at 1). Returning an error ends the run.
+type Hook func(tick int, w world.World) error
+
+// Step advances the world by a single tick. It is the same sequence the game
+// loop runs between frames.
+func Step(w world.World) {
+	w.Tick()
+	w.NpcMove()
+}
+
+type Runner struct {
+	World world.World
+	Hooks []Hook
+}
+
+// Run steps the world up to ticks times, calling the hooks after each step.
+// It returns the number of steps taken and the first hook error other than
+// ErrStop.
+func (r Runner) Run(ticks int) (int, error) {
+	for tick := 1; tick <= ticks; tick++ {
+		Step(r.World)
+		for _, hook := range r.Hooks {
+			if err := hook(tick, r.World); err != nil {
+				if errors.Is(err, ErrStop) {
+					return tick, nil
+				}
+				return tick, err
+			}
+		}
+	}
+	return ticks, nil
+}
@@ -0,0 +1,83 @@
+package sim_test
+
+import (
+	"errors"
+	"gobotworld/src/sim"
+	"gobotworld/src/world"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunStepsWorld(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	w := world.SeededWorld(logger, 1)
+
+	calls := 0
+	runner := sim.Runner{World: w, Hooks: []sim.Hook{
+		func(tick int, w world.World) error {
+			calls++
+			assert.Equal(t, tick, *w.Time, "Hook should see the world after the step")
+			return nil
+		},
+	}}
+
+	ran, err := runner.Run(100)
+	assert.NoError(t, err)
+	assert.Equal(t, 100, ran, "Runner should take every step")
+	assert.Equal(t, 100, calls, "Hook should be called once per step")
+	assert.Equal(t, 100, *w.Time, "World time should advance once per step")
+}
+
+func TestRunStopsEarly(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	w := world.SeededWorld(logger, 1)
+
+	runner := sim.Runner{World: w, Hooks: []sim.Hook{
+		func(tick int, _ world.World) error {
+			if tick == 10 {
+				return sim.ErrStop
+			}
+			return nil
+		},
+	}}
+
+	ran, err := runner.Run(100)
+	assert.NoError(t, err, "ErrStop should not be reported as a failure")
+	assert.Equal(t, 10, ran, "Runner should stop on the tick the hook asked to")
+}
+
+func TestRunReturnsHookError(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	w := world.SeededWorld(logger, 1)
+	failure := errors.New("boom")
+
+	runner := sim.Runner{World: w, Hooks: []sim.Hook{
+		func(int, world.World) error { return failure },
+	}}
+
+	ran, err := runner.Run(100)
+	assert.ErrorIs(t, err, failure)
+	assert.Equal(t, 1, ran)
+}
+
+func TestSameSeedSameRun(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	first := world.SeededWorld(logger, 9)
+	second := world.SeededWorld(logger, 9)
+
+	_, _ = sim.Runner{World: first}.Run(200)
+	_, _ = sim.Runner{World: second}.Run(200)
+
+	for being := range first.Beings {
+		found := false
+		for other := range second.Beings {
+			if other.Ident() == being.Ident() && *other.Location == *being.Location {
+				found = true
+			}
+		}
+		assert.True(t, found, "Every being should end in the same place for the same seed")
+	}
+}
@@ -0,0 +1,69 @@
+package terminal
+
+import (
+	"gobotworld/src/world"
+	"gobotworld/src/world/object"
+	"sync"
+
+	"github.com/gdamore/tcell/v2"
+)
+
+// keyBuffer is how many presses are kept for the ticks to come. Any more are
+// dropped so holding a key down doesn't keep the being walking after it's let go.
+const keyBuffer = 4
+
+var keyDirections = map[tcell.Key]object.Direction{
+	tcell.KeyLeft:  object.West,
+	tcell.KeyRight: object.East,
+	tcell.KeyUp:    object.North,
+	tcell.KeyDown:  object.South,
+}
+
+// keyActions are the letters that act on the cell the being faces.
+var keyActions = map[rune]world.ActionKind{
+	't': world.ActionTake,
+	'p': world.ActionPlace,
+}
+
+// Keyboard is a world.Controller that moves a being with the arrow keys, and
+// takes and places lights with t and p, one press a tick. Keys are handled on
+// the goroutine polling for events and acted on by the one ticking the world.
+type Keyboard struct {
+	mu      sync.Mutex
+	pending []world.Action
+}
+
+// HandleKey takes the keys the keyboard acts on and reports whether ev was
+// one of them.
+func (k *Keyboard) HandleKey(ev *tcell.EventKey) bool {
+	var action world.Action
+	if direction, ok := keyDirections[ev.Key()]; ok {
+		action = world.Action{Kind: world.ActionMove, Direction: direction}
+	} else if kind, ok := keyActions[ev.Rune()]; ok && ev.Key() == tcell.KeyRune {
+		action = world.Action{Kind: kind}
+	} else {
+		return false
+	}
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if len(k.pending) < keyBuffer {
+		k.pending = append(k.pending, action)
+	}
+	return true
+}
+
+// Act does what the oldest key pressed asks, or waits if there is none. Lights
+// are taken from and placed on the cell the being faces.
+func (k *Keyboard) Act(obs world.Observation) world.Action {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+	if len(k.pending) == 0 {
+		return world.Action{Kind: world.ActionWait}
+	}
+	action := k.pending[0]
+	k.pending = k.pending[1:]
+	if action.Kind != world.ActionMove {
+		action.Direction = obs.Facing
+	}
+	return action
+}
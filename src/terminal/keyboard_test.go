@@ -0,0 +1,45 @@
+package terminal
+
+import (
+	"gobotworld/src/world"
+	"gobotworld/src/world/object"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func key(k tcell.Key) *tcell.EventKey {
+	return tcell.NewEventKey(k, 0, tcell.ModNone)
+}
+
+func TestKeyboard(t *testing.T) {
+	var k Keyboard
+	assert.True(t, k.HandleKey(key(tcell.KeyUp)))
+	assert.True(t, k.HandleKey(key(tcell.KeyLeft)))
+	assert.False(t, k.HandleKey(key(tcell.KeyTab)), "Only arrow keys move")
+
+	assert.Equal(t, world.MoveAction(nil, object.North), k.Act(world.Observation{}))
+	assert.Equal(t, world.MoveAction(nil, object.West), k.Act(world.Observation{}), "Presses are acted on in order, one a tick")
+	assert.Equal(t, world.WaitAction(nil), k.Act(world.Observation{}))
+
+	for range keyBuffer * 2 {
+		k.HandleKey(key(tcell.KeyDown))
+	}
+	moves := 0
+	for k.Act(world.Observation{}).Kind == world.ActionMove {
+		moves++
+	}
+	assert.Equal(t, keyBuffer, moves, "Held keys shouldn't pile up")
+}
+
+func TestKeyboardLights(t *testing.T) {
+	var k Keyboard
+	assert.True(t, k.HandleKey(tcell.NewEventKey(tcell.KeyRune, 't', tcell.ModNone)))
+	assert.True(t, k.HandleKey(tcell.NewEventKey(tcell.KeyRune, 'p', tcell.ModNone)))
+	assert.False(t, k.HandleKey(tcell.NewEventKey(tcell.KeyRune, 'x', tcell.ModNone)))
+
+	facing := world.Observation{Facing: object.East}
+	assert.Equal(t, world.TakeAction(nil, object.East), k.Act(facing), "Lights are taken from the cell faced")
+	assert.Equal(t, world.PlaceAction(nil, object.East), k.Act(facing))
+}
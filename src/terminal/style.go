@@ -10,11 +10,6 @@ var (
 	DayGreen = tcell.NewRGBColor(0x66, 0x66, 0x00)
 	DayGray  = tcell.NewRGBColor(0x00, 0x99, 0x99)
 	fire1    = tcell.NewRGBColor(0xFA, 0xC0, 0x00)
-	fire2    = tcell.NewRGBColor(0xFF, 0x75, 0x00)
-	fire3    = tcell.NewRGBColor(0xFC, 0x64, 0x00)
-	fire4    = tcell.NewRGBColor(0xD7, 0x35, 0x02)
-	fire5    = tcell.NewRGBColor(0xB6, 0x22, 0x03)
-	fire6    = tcell.NewRGBColor(0x80, 0x11, 0x00)
 )
 
 func Tint(c tcell.Color, factor float32) tcell.Color {
@@ -22,12 +17,31 @@ func Tint(c tcell.Color, factor float32) tcell.Color {
 	return tcell.NewRGBColor(int32(float32(r)*factor), int32(float32(g)*factor), int32(float32(b)*factor))
 }
 
+// Blend mixes c with another colour, factor 0 leaves c as it is and 1 gives
+// the other colour.
+func Blend(c, with tcell.Color, factor float32) tcell.Color {
+	factor = min(max(factor, 0), 1)
+	r1, g1, b1 := c.RGB()
+	r2, g2, b2 := with.RGB()
+	mix := func(a, b int32) int32 {
+		return a + int32(float32(b-a)*factor)
+	}
+	return tcell.NewRGBColor(mix(r1, r2), mix(g1, g2), mix(b1, b2))
+}
+
 func TintStyleBackground(c tcell.Style, factor float32) tcell.Style {
 	fg, bg, _ := c.Decompose()
 	return tcell.StyleDefault.Foreground(fg).Background(Tint(bg, factor))
 }
 
+// TintStyle is Tint applied to both the foreground and background of a style.
+func TintStyle(c tcell.Style, factor float32) tcell.Style {
+	fg, bg, _ := c.Decompose()
+	return tcell.StyleDefault.Foreground(Tint(fg, factor)).Background(Tint(bg, factor))
+}
+
 var (
+	unseenStyle  = tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorBlack)
 	displayStyle = tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorWhite)
 	borderStyle  = tcell.StyleDefault.Foreground(tcell.ColorWhite).Background(tcell.ColorBlack)
 
@@ -41,11 +55,6 @@ var (
 	nightPlayerStyle = tcell.StyleDefault.Foreground(tcell.ColorRed).Background(tcell.ColorBlack)
 
 	fire1Style = tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(fire1)
-	fire2Style = tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(fire2)
-	fire3Style = tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(fire3)
-	fire4Style = tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(fire4)
-	fire5Style = tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(fire5)
-	fire6Style = tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(fire6)
 
 	pathStyle = tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(tcell.ColorWhite)
 )
@@ -72,6 +81,7 @@ var terrainSymbols = map[object.ObjectType]display{
 	object.PlayerType:   {'M', StyleTypePlayer},
 	object.EnemyType:    {'E', StyleTypePlayer},
 	object.TorchType:    {'^', StyleTypeLight},
+	object.CritterType:  {'c', StyleTypePlayer},
 }
 
 type RuneStyle struct {
@@ -79,6 +89,17 @@ type RuneStyle struct {
 	Style  tcell.Style
 }
 
+// dayGlare is how much of the colour of a light shows through daylight.
+const dayGlare = 0.5
+
+// litStyle is the style of a lit cell, its background blended from the unlit
+// background to the colour of the light by how brightly the cell is lit.
+func litStyle(unlit tcell.Color, light object.LightBlock, glare float32) tcell.Style {
+	lumen := min(max(light.Lumen, 1), object.MaxLumen)
+	colour := tcell.NewRGBColor(int32(light.Colour.R), int32(light.Colour.G), int32(light.Colour.B))
+	return tcell.StyleDefault.Foreground(tcell.ColorBlack).Background(Blend(unlit, colour, glare*float32(lumen)/object.MaxLumen))
+}
+
 func FindRuneStyle(obj object.Thing, light object.LightBlock) RuneStyle {
 	if light.Time == object.DayTime {
 		return dayRuneStyle(obj, light)
@@ -91,16 +112,8 @@ func nightRuneStyle(obj object.Thing, light object.LightBlock) RuneStyle {
 	symbol := terrainSymbols[obj.Ident().Type]
 	style := borderStyle
 	switch {
-	case light.Lumen > 4:
-		style = fire5Style
-	case light.Lumen > 3:
-		style = fire4Style
-	case light.Lumen > 2:
-		style = fire3Style
-	case light.Lumen > 1:
-		style = fire2Style
 	case light.Lumen > 0:
-		style = fire1Style
+		style = litStyle(tcell.ColorBlack, light, 1)
 	case symbol.StyleType == StyleTypeLight:
 		style = fire1Style
 	case symbol.StyleType == StyleTypeDefault:
@@ -118,12 +131,8 @@ func dayRuneStyle(obj object.Thing, light object.LightBlock) RuneStyle {
 	symbol := terrainSymbols[obj.Ident().Type]
 	style := borderStyle
 	switch {
-	case light.Lumen > 2:
-		style = fire4Style
-	case light.Lumen > 1:
-		style = fire3Style
 	case light.Lumen > 0:
-		style = fire2Style
+		style = litStyle(DayGreen, light, dayGlare)
 	case symbol.StyleType == StyleTypeLight:
 		style = fire1Style
 	case symbol.StyleType == StyleTypeDefault:
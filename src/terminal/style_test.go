@@ -1,7 +1,9 @@
 package terminal
 
 import (
+	"gobotworld/src/world"
 	"gobotworld/src/world/object"
+	"image/color"
 	"testing"
 
 	"github.com/gdamore/tcell/v2"
@@ -29,14 +31,21 @@ func TestTintStyleBackground(t *testing.T) {
 	assert.Equal(t, int32(50), b, "Blue component of background should be tinted to 50")
 }
 
+func TestTintStyle(t *testing.T) {
+	originalStyle := tcell.StyleDefault.Foreground(tcell.NewRGBColor(200, 100, 0)).Background(tcell.NewRGBColor(100, 100, 100))
+	fg, bg, _ := TintStyle(originalStyle, 0.5).Decompose()
+	assert.Equal(t, tcell.NewRGBColor(100, 50, 0), fg, "Foreground should be tinted")
+	assert.Equal(t, tcell.NewRGBColor(50, 50, 50), bg, "Background should be tinted")
+}
+
 func TestFindRuneStyle_DayTime(t *testing.T) {
 	obj := object.NewObject(1, object.PlayerType, true)
-	light := object.LightBlock{Time: object.DayTime, Lumen: 2}
+	light := object.LightBlock{Time: object.DayTime, Lumen: 6, Colour: object.TorchColour}
 
 	runeStyle := FindRuneStyle(obj, light)
 
 	assert.Equal(t, 'M', runeStyle.Symbol, "Symbol for PlayerType should be 'M'")
-	assert.Equal(t, fire3Style.Background(tcell.ColorBlack), runeStyle.Style.Background(tcell.ColorBlack), "Style should match fire3Style for Lumen > 1")
+	assert.Equal(t, litStyle(DayGreen, light, dayGlare), runeStyle.Style, "Lit cells by day should be the daylight blended with the light")
 }
 
 func TestFindRuneStyle_NightTime(t *testing.T) {
@@ -51,12 +60,12 @@ func TestFindRuneStyle_NightTime(t *testing.T) {
 
 func TestFindRuneStyle_NightTime_Light(t *testing.T) {
 	obj := object.NewObject(1, object.TorchType, true)
-	light := object.LightBlock{Time: object.NightTime, Lumen: 3}
+	light := object.LightBlock{Time: object.NightTime, Lumen: 6, Colour: object.TorchColour}
 
 	runeStyle := FindRuneStyle(obj, light)
 
 	assert.Equal(t, '^', runeStyle.Symbol, "Symbol for TorchType should be '^'")
-	assert.Equal(t, fire4Style.Background(tcell.ColorBlack), runeStyle.Style.Background(tcell.ColorBlack), "Style should match fire4Style for Lumen > 3")
+	assert.Equal(t, litStyle(tcell.ColorBlack, light, 1), runeStyle.Style, "Lit cells by night should be the dark blended with the light")
 }
 
 func TestDayRuneStyle(t *testing.T) {
@@ -78,3 +87,38 @@ func TestNightRuneStyle(t *testing.T) {
 	assert.Equal(t, '@', runeStyle.Symbol, "Symbol for ObstacleType should be '@'")
 	assert.Equal(t, nightObstacleStyle.Background(tcell.ColorBlack), runeStyle.Style.Background(tcell.ColorBlack), "Style should match nightObstacleStyle for StyleTypeObstacle at NightTime")
 }
+
+func TestBlend(t *testing.T) {
+	a, b := tcell.NewRGBColor(0, 100, 200), tcell.NewRGBColor(200, 100, 0)
+	assert.Equal(t, a, Blend(a, b, 0))
+	assert.Equal(t, b, Blend(a, b, 1))
+	assert.Equal(t, tcell.NewRGBColor(100, 100, 100), Blend(a, b, 0.5))
+	assert.Equal(t, b, Blend(a, b, 2), "Blending stops at the other colour")
+}
+
+func TestLitStyle(t *testing.T) {
+	blue := color.RGBA{B: 0xFF, A: 0xFF}
+	background := func(light object.LightBlock, glare float32) tcell.Color {
+		_, bg, _ := litStyle(tcell.ColorBlack, light, glare).Decompose()
+		return bg
+	}
+	assert.Equal(t, tcell.NewRGBColor(0, 0, 0xFF), background(object.LightBlock{Lumen: object.MaxLumen, Colour: blue}, 1), "The brightest light is the colour of the light")
+	assert.Equal(t, tcell.NewRGBColor(0, 0, 0xFF), background(object.LightBlock{Lumen: object.MaxLumen + 5, Colour: blue}, 1))
+	assert.Equal(t, tcell.NewRGBColor(0, 0, 0x7F), background(object.LightBlock{Lumen: object.MaxLumen, Colour: blue}, 0.5), "Glare holds the colour back")
+
+	red := color.RGBA{R: 0xFF, A: 0xFF}
+	last := int32(0)
+	for lumen := 1; lumen <= object.MaxLumen; lumen++ {
+		r, g, b := background(object.LightBlock{Lumen: lumen, Colour: red}, 1).RGB()
+		assert.Greater(t, r, last, "More light is brighter")
+		assert.Zero(t, g+b, "Only the colour of the light shows")
+		last = r
+	}
+}
+
+func TestTerrainSymbolsMatchMapLegend(t *testing.T) {
+	for glyph, objType := range world.DefaultLegend {
+		assert.Equal(t, glyph, terrainSymbols[objType].Symbol, "Maps should be drawn with the glyph the terminal uses for %s", objType)
+	}
+	assert.Len(t, world.DefaultLegend, len(terrainSymbols), "Every glyph the terminal draws should be usable in a map")
+}
@@ -7,6 +7,7 @@ import (
 	"gobotworld/src/world/object"
 	"image"
 	"log"
+	"slices"
 
 	"github.com/gdamore/tcell/v2"
 )
@@ -15,8 +16,11 @@ var DefaultDisplayLength = 15
 
 type Terminal struct {
 	CommandWidth int
-	screen       tcell.Screen
-	Logger       *log.Logger
+	// Debug lists the state of bot programs and what the nearest NPCs are
+	// doing down the side of the screen.
+	Debug  bool
+	screen tcell.Screen
+	Logger *log.Logger
 }
 
 func Init() (Terminal, error) {
@@ -62,11 +66,14 @@ func (t Terminal) PrintPath(path map[image.Point]bool) {
 
 func (t Terminal) DrawWorld(gameWorld world.World) {
 	playerLocation := *gameWorld.Player.Location
-	wnd := t.drawWindow(playerLocation, gameWorld.Geography.Height(), gameWorld.Geography.Width())
-	cycle, count := object.Time(*gameWorld.Time)
+	wnd := t.drawWindow(playerLocation, gameWorld.Geography.Bounds())
+	lights := gameWorld.LightSources()
+	cycle, count := gameWorld.Cycle()
 	pathFinder := world.PathFinder{World: gameWorld, Logger: t.Logger}
+	fov := gameWorld.Visible(gameWorld.Player)
+	memory := gameWorld.Memory(gameWorld.Player)
 
-	nearestLight := gameWorld.Lights.NearestLight(playerLocation)
+	nearestLight := lights.NearestLight(playerLocation)
 	if nearestLight.X == -1 && nearestLight.Y == -1 {
 		if t.Logger != nil {
 			t.Logger.Println("No nearest light found.")
@@ -87,12 +94,18 @@ func (t Terminal) DrawWorld(gameWorld world.World) {
 		t.SetCell(w-t.CommandWidth, y, RuneStyle{Symbol: '|', Style: borderStyle})
 
 		for row := wnd.Left; row < wnd.Width; row++ {
-			pt := gameWorld.Geography[col][row]
 			loc := image.Point{X: row, Y: col}
+			pt := gameWorld.Geography.At(loc)
 
-			light := LightValue(loc, wnd, gameWorld.Lights, cycle)
-			sense := SenseValue(loc, *gameWorld.Player.Location, gameWorld.Player.Direction)
-			runeStyle := drawCell(pt, light, sense)
+			light := world.Vision(loc, gameWorld)
+			sense := SenseValue(loc, fov)
+			runeStyle := RuneStyle{Symbol: ' ', Style: unseenStyle}
+			if fov.Sees(loc) {
+				runeStyle = drawCell(pt, light, sense)
+			} else if remembered, ok := memory.Recall(loc); ok {
+				runeStyle = drawCell(object.ThingList{object.NewObject(0, remembered, false)}, light, 1)
+				runeStyle.Style = TintStyle(runeStyle.Style, sense)
+			}
 
 			if path != nil {
 				if ok := path[loc]; ok {
@@ -121,14 +134,93 @@ func (t Terminal) DrawWorld(gameWorld world.World) {
 	}
 	str = fmt.Sprintf("%s %d", c, count)
 	t.screen.SetContent(w-t.CommandWidth+1, 3, ' ', []rune(str), borderStyle)
+
+	if t.Debug {
+		next := t.drawInspectors(gameWorld, w-t.CommandWidth+1, 5)
+		t.drawBehaviours(gameWorld, w-t.CommandWidth+1, next)
+	}
+}
+
+// inspector is a controller that can describe its state, such as a bot
+// program.
+type inspector interface {
+	Inspect() []string
+}
+
+// drawInspectors shows the state of every controller that can describe it,
+// from (x, y) down, and returns the row after the last one drawn.
+func (t Terminal) drawInspectors(gameWorld world.World, x, y int) int {
+	drawn := false
+	for _, being := range gameWorld.Roster() {
+		c, _ := gameWorld.Controller(being)
+		in, ok := c.(inspector)
+		if !ok {
+			continue
+		}
+		if !drawn {
+			t.print(x, y, "::Bots::", borderStyle)
+			y++
+			drawn = true
+		}
+		for _, line := range in.Inspect() {
+			t.print(x, y, line, borderStyle)
+			y++
+		}
+		y++
+	}
+	return y
+}
+
+// drawBehaviours lists the state of the NPCs nearest the player, one per line
+// from (x, y) down to the bottom of the screen.
+func (t Terminal) drawBehaviours(gameWorld world.World, x, y int) {
+	_, h := t.screen.Size()
+	t.print(x, y, "::NPCs::", borderStyle)
+
+	var npcs []*object.Character
+	for being, controlled := range gameWorld.Beings {
+		if !controlled {
+			npcs = append(npcs, being)
+		}
+	}
+	player := *gameWorld.Player.Location
+	slices.SortFunc(npcs, func(a, b *object.Character) int {
+		if d := geometry.Distance(*a.Location, player) - geometry.Distance(*b.Location, player); d != 0 {
+			return d
+		}
+		return a.Ident().Index - b.Ident().Index
+	})
+
+	for _, npc := range npcs {
+		y++
+		if y >= h {
+			return
+		}
+		state := "?"
+		if b, ok := gameWorld.Behaviour(npc); ok {
+			state = b.State.String()
+		}
+		str := fmt.Sprintf("%c%d %s", terrainSymbols[npc.Ident().Type].Symbol, npc.Ident().Index, state)
+		t.print(x, y, str, borderStyle)
+	}
+}
+
+// print writes str from (x, y) one rune per cell.
+func (t Terminal) print(x, y int, str string, style tcell.Style) {
+	for _, r := range str {
+		t.screen.SetContent(x, y, r, nil, style)
+		x++
+	}
 }
 
 func drawCell(l object.ThingList, light object.LightBlock, bgFactor float32) RuneStyle {
 	runeStyle := RuneStyle{Symbol: 'X', Style: borderStyle}
 
+	// Things put on a cell later are drawn over those of the same index, so a
+	// torch placed on the floor shows
 	idx := -1
 	for _, obj := range l {
-		if obj.Ident().Index > idx {
+		if obj.Ident().Index >= idx {
 			idx = obj.Ident().Index
 			runeStyle = FindRuneStyle(obj, light)
 		}
@@ -138,7 +230,9 @@ func drawCell(l object.ThingList, light object.LightBlock, bgFactor float32) Run
 	return runeStyle
 }
 
-func (t Terminal) drawWindow(player image.Point, worldHeight, worldWidth int) geometry.Window {
+// drawWindow centres the screen on the player, keeping it inside the bounds of
+// the map where it can.
+func (t Terminal) drawWindow(player image.Point, bounds image.Rectangle) geometry.Window {
 	w, h := t.screen.Size()
 	w -= t.CommandWidth
 	h -= 1
@@ -148,24 +242,27 @@ func (t Terminal) drawWindow(player image.Point, worldHeight, worldWidth int) ge
 
 	left := player.X - (w / 2)
 	width := player.X + (w / 2)
-	if left < 0 {
-		left = 0
-		width = w
+	if left < bounds.Min.X {
+		left = bounds.Min.X
+		width = bounds.Min.X + w
 	}
-	if width >= worldWidth {
-		left = worldWidth - w
-		width = worldWidth
+	if width >= bounds.Max.X {
+		left = bounds.Max.X - w
+		width = bounds.Max.X
 	}
 	top := player.Y - (h / 2)
 	height := player.Y + (h / 2)
-	if top < 0 {
-		top = 1
-		height = h
+	if top < bounds.Min.Y {
+		top = bounds.Min.Y + 1
+		height = bounds.Min.Y + h
 	}
-	if height >= worldHeight {
-		top = worldHeight - h
-		height = worldHeight
+	if height >= bounds.Max.Y {
+		top = bounds.Max.Y - h
+		height = bounds.Max.Y
 	}
+	// Maps smaller than the screen are drawn from the top left
+	left = max(left, bounds.Min.X)
+	top = max(top, bounds.Min.Y)
 
 	return geometry.Window{Left: left, Top: top, Width: width, Height: height}
 }
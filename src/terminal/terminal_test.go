@@ -0,0 +1,130 @@
+package terminal
+
+import (
+	"gobotworld/src/world"
+	"gobotworld/src/world/object"
+	"image"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func simulatedTerminal(t *testing.T, width, height int) (Terminal, tcell.SimulationScreen) {
+	s := tcell.NewSimulationScreen("UTF-8")
+	require.NoError(t, s.Init())
+	s.SetSize(width, height)
+	return Terminal{screen: s, CommandWidth: DefaultDisplayLength, Logger: log.New(io.Discard, "", log.LstdFlags)}, s
+}
+
+// line reads the text on row y of the screen starting at x.
+func line(s tcell.SimulationScreen, x, y int) string {
+	w, _ := s.Size()
+	var sb strings.Builder
+	for ; x < w; x++ {
+		r, _, _, _ := s.GetContent(x, y)
+		sb.WriteRune(r)
+	}
+	return strings.TrimSpace(sb.String())
+}
+
+func TestDrawBehaviours(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	near := image.Point{X: 12, Y: 10}
+	gameWorld := world.InitWorld(logger, 30, 30, world.EmptyConfig().WithSeed(1).
+		WithPlayerSpawn(image.Point{X: 10, Y: 10}).
+		WithNPCs(world.NPCSpawn{Type: object.CritterType, Location: &near}))
+	// Nothing is drawn without a light to find the way to
+	*gameWorld.Lights = object.Lights{object.NewLight(image.Point{X: 1, Y: 1}, 4)}
+	gameWorld.NpcMove()
+
+	term, s := simulatedTerminal(t, 60, 20)
+	term.Debug = true
+	term.DrawWorld(gameWorld)
+
+	x := 60 - term.CommandWidth + 1
+	assert.Equal(t, "::NPCs::", line(s, x, 5))
+	assert.Equal(t, "c2 flee", line(s, x, 6), "The nearest NPC should be listed with its state")
+}
+
+type inspected []string
+
+func (i inspected) Act(world.Observation) world.Action { return world.WaitAction(nil) }
+func (i inspected) Inspect() []string                  { return i }
+
+func TestDrawInspectors(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	gameWorld := world.InitWorld(logger, 30, 30, world.EmptyConfig().WithSeed(1).WithNPCs())
+	term, s := simulatedTerminal(t, 60, 20)
+
+	assert.Equal(t, 5, term.drawInspectors(gameWorld, 0, 5), "Nothing is drawn without a bot")
+
+	gameWorld.SetController(gameWorld.Player, inspected{"bot pc 3", "r0 1 r1 2"})
+	assert.Equal(t, 9, term.drawInspectors(gameWorld, 0, 5))
+	assert.Equal(t, "::Bots::", line(s, 0, 5))
+	assert.Equal(t, "bot pc 3", line(s, 0, 6))
+	assert.Equal(t, "r0 1 r1 2", line(s, 0, 7))
+}
+
+func TestDrawFogOfWar(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	am, err := world.ReadASCIIMap(strings.NewReader(strings.Join([]string{
+		"@@@@@@@@",
+		"@Mo@.^.@",
+		"@..@...@",
+		"@......@",
+		"@@@@@@@@",
+	}, "\n")))
+	require.NoError(t, err)
+	gameWorld := am.NewWorld(logger, world.NewConfig().WithNPCs().WithSeed(1))
+	term, s := simulatedTerminal(t, 60, 20)
+
+	// The map is smaller than the screen so it is drawn from the top left,
+	// below the status line
+	cell := func(p image.Point) (rune, tcell.Style) {
+		r, _, style, _ := s.GetContent(p.X, p.Y+1)
+		return r, style
+	}
+	rock, hidden := image.Point{X: 2, Y: 1}, image.Point{X: 6, Y: 1}
+
+	term.DrawWorld(gameWorld)
+	r, seen := cell(rock)
+	assert.Equal(t, 'o', r)
+	r, style := cell(hidden)
+	assert.Equal(t, ' ', r, "Cells never seen are blank")
+	assert.Equal(t, unseenStyle, style)
+
+	gameWorld.Tick()
+	to := image.Point{X: 6, Y: 2}
+	gameWorld.Relocate(gameWorld.Player, to)
+
+	term.DrawWorld(gameWorld)
+	r, style = cell(rock)
+	assert.Equal(t, 'o', r, "Cells seen before are drawn from memory")
+	assert.Equal(t, TintStyle(seen, rememberedTint), style, "Remembered cells are dimmed")
+	r, _ = cell(hidden)
+	assert.Equal(t, '.', r, "Cells in sight are drawn as they are")
+}
+
+func TestDrawPlacedTorch(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	am, err := world.ReadASCIIMap(strings.NewReader(strings.Join([]string{
+		"@@@@@",
+		"@M  @",
+		"@@@@@",
+	}, "\n")))
+	require.NoError(t, err)
+	gameWorld := am.NewWorld(logger, world.NewConfig().WithNPCs().WithSeed(1))
+	gameWorld.Carry(gameWorld.Player, object.NewLantern(2))
+	require.True(t, gameWorld.Place(gameWorld.Player, object.East))
+	gameWorld.Tick()
+
+	term, s := simulatedTerminal(t, 60, 20)
+	term.DrawWorld(gameWorld)
+	r, _, _, _ := s.GetContent(2, 2)
+	assert.Equal(t, '^', r, "Torches placed on the floor are drawn over it")
+}
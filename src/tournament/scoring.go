@@ -0,0 +1,112 @@
+package tournament
+
+import (
+	"gobotworld/src/world"
+	"gobotworld/src/world/object"
+	"image"
+	"slices"
+)
+
+// Rule scores a team over a match. Tick is called with the beings of the team
+// where they start and again after every tick, and Score once the match is
+// over.
+type Rule interface {
+	Tick(w world.World, team []*object.Character)
+	Score() float64
+}
+
+// Rules are the scoring rules a tournament can use, by name.
+var Rules = map[string]func() Rule{
+	"explore": func() Rule { return &explore{seen: map[image.Point]bool{}} },
+	"torch":   func() Rule { return &torchTime{} },
+	"moves":   func() Rule { return &moves{last: map[*object.Character]image.Point{}} },
+	"caught":  func() Rule { return &caught{} },
+}
+
+// RuleNames returns the names of the rules in a stable order.
+func RuleNames() []string {
+	names := make([]string, 0, len(Rules))
+	for name := range Rules {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// explore scores every cell any of the team has stood on.
+type explore struct {
+	seen map[image.Point]bool
+}
+
+func (e *explore) Tick(_ world.World, team []*object.Character) {
+	for _, being := range team {
+		e.seen[*being.Location] = true
+	}
+}
+
+func (e *explore) Score() float64 {
+	return float64(len(e.seen))
+}
+
+// torchTime scores every tick each of the team spends next to a light.
+type torchTime struct {
+	ticks int
+}
+
+func (t *torchTime) Tick(w world.World, team []*object.Character) {
+	lights := w.LightSources()
+	for _, being := range team {
+		if slices.ContainsFunc(lights, func(light *object.Light) bool { return next(*being.Location, *light.Location) }) {
+			t.ticks++
+		}
+	}
+}
+
+func (t *torchTime) Score() float64 {
+	return float64(t.ticks)
+}
+
+// moves scores every step the team takes.
+type moves struct {
+	last  map[*object.Character]image.Point
+	steps int
+}
+
+func (m *moves) Tick(_ world.World, team []*object.Character) {
+	for _, being := range team {
+		if last, ok := m.last[being]; ok && last != *being.Location {
+			m.steps++
+		}
+		m.last[being] = *being.Location
+	}
+}
+
+func (m *moves) Score() float64 {
+	return float64(m.steps)
+}
+
+// caught scores -1 for every tick each of the team spends next to an enemy.
+type caught struct {
+	ticks int
+}
+
+func (c *caught) Tick(w world.World, team []*object.Character) {
+	for _, being := range team {
+		for other := range w.Beings {
+			if other.Ident().Type == object.EnemyType && !slices.Contains(team, other) && next(*being.Location, *other.Location) {
+				c.ticks++
+				break
+			}
+		}
+	}
+}
+
+func (c *caught) Score() float64 {
+	return -float64(c.ticks)
+}
+
+// next reports whether a and b are side by side.
+func next(a, b image.Point) bool {
+	d := a.Sub(b)
+	return max(d.X, -d.X)+max(d.Y, -d.Y) == 1
+}
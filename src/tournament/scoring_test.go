@@ -0,0 +1,57 @@
+package tournament
+
+import (
+	"gobotworld/src/world"
+	"gobotworld/src/world/object"
+	"image"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// arena has the player by a torch in the wall and an enemy two cells away.
+func arena(t *testing.T) (world.World, []*object.Character) {
+	am, err := world.ReadASCIIMap(strings.NewReader(strings.Join([]string{
+		"@@^@@@",
+		"@ M E@",
+		"@@@@@@",
+	}, "\n")))
+	require.NoError(t, err)
+	w := am.NewWorld(log.New(io.Discard, "", 0), world.NewConfig().WithNPCs().WithSeed(1))
+	return w, []*object.Character{w.Player}
+}
+
+func TestRuleNames(t *testing.T) {
+	assert.Equal(t, []string{"caught", "explore", "moves", "torch"}, RuleNames())
+}
+
+func TestExploreAndMoves(t *testing.T) {
+	w, team := arena(t)
+	e, m := Rules["explore"](), Rules["moves"]()
+	for _, to := range []image.Point{{X: 2, Y: 1}, {X: 1, Y: 1}, {X: 2, Y: 1}, {X: 2, Y: 1}} {
+		w.Relocate(w.Player, to)
+		e.Tick(w, team)
+		m.Tick(w, team)
+	}
+	assert.Equal(t, 2.0, e.Score(), "Cells only count once")
+	assert.Equal(t, 2.0, m.Score(), "Standing still isn't a move")
+}
+
+func TestTorchAndCaught(t *testing.T) {
+	w, team := arena(t)
+	torch, c := Rules["torch"](), Rules["caught"]()
+	torch.Tick(w, team)
+	c.Tick(w, team)
+	assert.Equal(t, 1.0, torch.Score())
+	assert.Zero(t, c.Score())
+
+	w.Relocate(w.Player, image.Point{X: 3, Y: 1})
+	torch.Tick(w, team)
+	c.Tick(w, team)
+	assert.Equal(t, 1.0, torch.Score())
+	assert.Equal(t, -1.0, c.Score(), "Being next to an enemy costs")
+}
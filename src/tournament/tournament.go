@@ -0,0 +1,287 @@
+// Package tournament plays bots against the same worlds headlessly and ranks
+// them, so bot strategies can be compared by their scores rather than by
+// watching them.
+package tournament
+
+import (
+	"encoding/json"
+	"fmt"
+	"gobotworld/src/bot"
+	"gobotworld/src/replay"
+	"gobotworld/src/sim"
+	"gobotworld/src/vm"
+	"gobotworld/src/world"
+	"io"
+	"log"
+	"os"
+	"slices"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Entry is a bot, or a team of bots, taking part. Bots are commands run as
+// processes and Programs are files run on the VM. The first of the team plays
+// the player and the rest take over the NPCs, bots before programs.
+type Entry struct {
+	Name     string   `json:"name"`
+	Bots     []string `json:"bots,omitempty"`
+	Programs []string `json:"programs,omitempty"`
+}
+
+func (e Entry) size() int {
+	return len(e.Bots) + len(e.Programs)
+}
+
+// Spec describes a tournament. Every entry plays every seed for Ticks ticks on
+// the world Config makes, the default world if it is empty. Scoring weighs the
+// rules, by name, that make up the score of a match.
+type Spec struct {
+	Config    string             `json:"config,omitempty"`
+	Ticks     int                `json:"ticks"`
+	Seeds     []int64            `json:"seeds"`
+	Scoring   map[string]float64 `json:"scoring"`
+	Budget    int                `json:"budget,omitempty"`
+	TimeoutMs int                `json:"timeoutMs,omitempty"`
+	Entries   []Entry            `json:"entries"`
+}
+
+func ReadSpec(r io.Reader) (Spec, error) {
+	var spec Spec
+	if err := json.NewDecoder(r).Decode(&spec); err != nil {
+		return Spec{}, fmt.Errorf("reading tournament: %w", err)
+	}
+	return spec, spec.Validate()
+}
+
+func LoadSpec(path string) (Spec, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return Spec{}, err
+	}
+	defer in.Close()
+	return ReadSpec(in)
+}
+
+func (spec Spec) Validate() error {
+	if spec.Ticks <= 0 {
+		return fmt.Errorf("ticks: must be positive, got %d", spec.Ticks)
+	}
+	if len(spec.Seeds) == 0 {
+		return fmt.Errorf("seeds: at least one is needed")
+	}
+	if len(spec.Scoring) == 0 {
+		return fmt.Errorf("scoring: at least one rule is needed, from %s", strings.Join(RuleNames(), ", "))
+	}
+	for name := range spec.Scoring {
+		if _, ok := Rules[name]; !ok {
+			return fmt.Errorf("scoring: unknown rule %q, expected one of %s", name, strings.Join(RuleNames(), ", "))
+		}
+	}
+	if len(spec.Entries) == 0 {
+		return fmt.Errorf("entries: at least one is needed")
+	}
+	names := map[string]bool{}
+	for i, e := range spec.Entries {
+		if e.Name == "" || names[e.Name] {
+			return fmt.Errorf("entries[%d].name: must be set and different for every entry, got %q", i, e.Name)
+		}
+		names[e.Name] = true
+		if e.size() == 0 {
+			return fmt.Errorf("entries[%d]: %s has no bots or programs", i, e.Name)
+		}
+	}
+	return nil
+}
+
+func (spec Spec) timeout() time.Duration {
+	if spec.TimeoutMs <= 0 {
+		return bot.DefaultTimeout
+	}
+	return time.Duration(spec.TimeoutMs) * time.Millisecond
+}
+
+func (spec Spec) budget() int {
+	if spec.Budget <= 0 {
+		return vm.DefaultBudget
+	}
+	return spec.Budget
+}
+
+// Match is one entry playing one seed.
+type Match struct {
+	Entry string
+	Seed  int64
+	// Scores holds what each rule scored before weighting.
+	Scores map[string]float64
+	Total  float64
+	Replay replay.File
+}
+
+// Run plays every match of the tournament on worlds made from fc, calling
+// done with each match as it finishes.
+func Run(logger *log.Logger, spec Spec, fc world.FileConfig, done func(Match) error) ([]Match, error) {
+	var matches []Match
+	for _, entry := range spec.Entries {
+		for _, seed := range spec.Seeds {
+			m, err := play(logger, spec, fc, entry, seed)
+			if err != nil {
+				return matches, fmt.Errorf("%s on seed %d: %w", entry.Name, seed, err)
+			}
+			logger.Printf("%s scored %g on seed %d", entry.Name, m.Total, seed)
+			if done != nil {
+				if err := done(m); err != nil {
+					return matches, err
+				}
+			}
+			matches = append(matches, m)
+		}
+	}
+	return matches, nil
+}
+
+func play(logger *log.Logger, spec Spec, fc world.FileConfig, entry Entry, seed int64) (Match, error) {
+	fc.Seed = &seed
+	w, err := fc.NewWorld(logger)
+	if err != nil {
+		return Match{}, err
+	}
+	roster := w.Roster()
+	if entry.size() > len(roster) {
+		return Match{}, fmt.Errorf("a team of %d but only %d beings", entry.size(), len(roster))
+	}
+	team := roster[:entry.size()]
+
+	processes, err := bot.Attach(logger, w, roster, spec.timeout(), entry.Bots)
+	if err != nil {
+		return Match{}, err
+	}
+	defer func() {
+		for _, p := range processes {
+			p.Close()
+		}
+	}()
+	if _, err := vm.Attach(logger, w, roster[len(processes):], spec.budget(), entry.Programs); err != nil {
+		return Match{}, err
+	}
+
+	rules := map[string]Rule{}
+	for name := range spec.Scoring {
+		rules[name] = Rules[name]()
+	}
+	// Rules see where the team starts so the first tick's steps count
+	for _, rule := range rules {
+		rule.Tick(w, team)
+	}
+	recorder := replay.NewRecorder(w)
+	runner := sim.Runner{World: w, Hooks: []sim.Hook{
+		func(_ int, w world.World) error {
+			for _, rule := range rules {
+				rule.Tick(w, team)
+			}
+			return nil
+		},
+	}}
+	if _, err := runner.Run(spec.Ticks); err != nil {
+		return Match{}, err
+	}
+
+	m := Match{Entry: entry.Name, Seed: seed, Scores: map[string]float64{}, Replay: recorder.Finish()}
+	m.Replay.Config = &fc
+	for name, rule := range rules {
+		m.Scores[name] = rule.Score()
+		m.Total += rule.Score() * spec.Scoring[name]
+	}
+	return m, nil
+}
+
+// Result is how an entry did over every seed.
+type Result struct {
+	Rank  int
+	Entry string
+	// Mean is the mean total score of its matches.
+	Mean float64
+	// Rules holds the mean score of each rule before weighting.
+	Rules  map[string]float64
+	Totals []float64
+}
+
+// Rank sums up the matches of each entry, best mean score first. Entries with
+// the same mean share a rank.
+func Rank(matches []Match) []Result {
+	var results []Result
+	byEntry := map[string]int{}
+	for _, m := range matches {
+		i, ok := byEntry[m.Entry]
+		if !ok {
+			i = len(results)
+			byEntry[m.Entry] = i
+			results = append(results, Result{Entry: m.Entry, Rules: map[string]float64{}})
+		}
+		r := &results[i]
+		r.Totals = append(r.Totals, m.Total)
+		for name, score := range m.Scores {
+			r.Rules[name] += score
+		}
+	}
+	for i := range results {
+		r := &results[i]
+		n := float64(len(r.Totals))
+		for _, total := range r.Totals {
+			r.Mean += total / n
+		}
+		for name := range r.Rules {
+			r.Rules[name] /= n
+		}
+	}
+
+	slices.SortStableFunc(results, func(a, b Result) int {
+		switch {
+		case a.Mean > b.Mean:
+			return -1
+		case a.Mean < b.Mean:
+			return 1
+		}
+		return 0
+	})
+	for i := range results {
+		results[i].Rank = i + 1
+		if i > 0 && results[i].Mean == results[i-1].Mean {
+			results[i].Rank = results[i-1].Rank
+		}
+	}
+	return results
+}
+
+// WriteTable writes the results as a table, one row per entry, with the mean
+// of each rule and the total of each seed.
+func WriteTable(w io.Writer, spec Spec, results []Result) error {
+	var rules []string
+	for _, name := range RuleNames() {
+		if _, ok := spec.Scoring[name]; ok {
+			rules = append(rules, name)
+		}
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := []string{"rank", "entry", "mean"}
+	for _, name := range rules {
+		header = append(header, fmt.Sprintf("%s x%g", name, spec.Scoring[name]))
+	}
+	for _, seed := range spec.Seeds {
+		header = append(header, fmt.Sprintf("seed %d", seed))
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t")+"\t")
+
+	for _, r := range results {
+		row := []string{fmt.Sprint(r.Rank), r.Entry, fmt.Sprintf("%.1f", r.Mean)}
+		for _, name := range rules {
+			row = append(row, fmt.Sprintf("%.1f", r.Rules[name]))
+		}
+		for _, total := range r.Totals {
+			row = append(row, fmt.Sprintf("%.1f", total))
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t")+"\t")
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,168 @@
+package tournament
+
+import (
+	"bytes"
+	"gobotworld/src/replay"
+	"gobotworld/src/world"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// program writes a bot program to a file and returns its path.
+func program(t *testing.T, name string, lines ...string) string {
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o644))
+	return path
+}
+
+func testConfig() world.FileConfig {
+	fc := world.DefaultFileConfig()
+	fc.Width, fc.Height = 40, 40
+	fc.NPCs = []world.NPCConfig{{Type: "enemy", Count: 2}}
+	return fc
+}
+
+func testSpec(t *testing.T) Spec {
+	return Spec{
+		Ticks:   50,
+		Seeds:   []int64{1, 2},
+		Scoring: map[string]float64{"explore": 1, "moves": 0.5},
+		Entries: []Entry{
+			{Name: "sitter", Programs: []string{program(t, "sit.asm", "wait")}},
+			{Name: "explorer", Programs: []string{"../../bots/explorer.asm"}},
+			{Name: "team", Programs: []string{"../../bots/explorer.asm", "../../bots/explorer.asm"}},
+		},
+	}
+}
+
+func TestValidate(t *testing.T) {
+	require.NoError(t, testSpec(t).Validate())
+
+	for _, tc := range []struct {
+		change func(*Spec)
+		err    string
+	}{
+		{func(s *Spec) { s.Ticks = 0 }, "ticks: must be positive, got 0"},
+		{func(s *Spec) { s.Seeds = nil }, "seeds: at least one is needed"},
+		{func(s *Spec) { s.Scoring = map[string]float64{"gold": 1} }, `scoring: unknown rule "gold", expected one of caught, explore, moves, torch`},
+		{func(s *Spec) { s.Entries[1].Name = "sitter" }, `entries[1].name: must be set and different for every entry, got "sitter"`},
+		{func(s *Spec) { s.Entries[0].Programs = nil }, "entries[0]: sitter has no bots or programs"},
+	} {
+		spec := testSpec(t)
+		tc.change(&spec)
+		assert.EqualError(t, spec.Validate(), tc.err)
+	}
+}
+
+func TestReadSpec(t *testing.T) {
+	spec, err := ReadSpec(strings.NewReader(`{"ticks": 10, "seeds": [4], "scoring": {"torch": 2}, "entries": [{"name": "a", "bots": ["./a"]}]}`))
+	require.NoError(t, err)
+	assert.Equal(t, Spec{Ticks: 10, Seeds: []int64{4}, Scoring: map[string]float64{"torch": 2}, Entries: []Entry{{Name: "a", Bots: []string{"./a"}}}}, spec)
+
+	_, err = ReadSpec(strings.NewReader(`{"ticks": 10}`))
+	assert.Error(t, err)
+}
+
+func TestRun(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	spec := testSpec(t)
+
+	var done []string
+	matches, err := Run(logger, spec, testConfig(), func(m Match) error {
+		done = append(done, m.Entry)
+		return nil
+	})
+	require.NoError(t, err)
+	require.Len(t, matches, 6, "Every entry plays every seed")
+	assert.Equal(t, []string{"sitter", "sitter", "explorer", "explorer", "team", "team"}, done)
+
+	sitter := matches[0]
+	assert.Equal(t, map[string]float64{"explore": 1, "moves": 0}, sitter.Scores, "Sitting still only sees the start")
+	assert.Equal(t, 1.0, sitter.Total)
+
+	explorer := matches[2]
+	assert.Equal(t, explorer.Scores["explore"]+explorer.Scores["moves"]*0.5, explorer.Total, "Totals are weighted")
+
+	again, err := Run(logger, spec, testConfig(), nil)
+	require.NoError(t, err)
+	assert.Equal(t, matches[3].Total, again[3].Total, "The same seed plays the same match")
+
+	for _, m := range matches {
+		_, err := replay.Play(logger, m.Replay, nil)
+		assert.NoError(t, err, "%s on seed %d should replay", m.Entry, m.Seed)
+	}
+}
+
+func TestFirstStepScores(t *testing.T) {
+	fc := testConfig()
+	fc.Width, fc.Height, fc.NPCs = 0, 0, nil
+	fc.Generator = &world.GeneratorConfig{Type: "ascii", Path: program(t, "room.txt",
+		"@@@@@",
+		"@   @",
+		"@ M @",
+		"@   @",
+		"@@@@@",
+	)}
+	spec := Spec{
+		Ticks:   1,
+		Seeds:   []int64{1},
+		Scoring: map[string]float64{"explore": 1, "moves": 1},
+		Entries: []Entry{{Name: "stepper", Programs: []string{program(t, "step.asm", "move")}}},
+	}
+
+	matches, err := Run(log.New(io.Discard, "", 0), spec, fc, nil)
+	require.NoError(t, err)
+	require.Len(t, matches, 1)
+	assert.Equal(t, 1.0, matches[0].Scores["moves"], "A step on the first tick is a move")
+	assert.Equal(t, 2.0, matches[0].Scores["explore"], "The cell started on counts as well")
+}
+
+func TestRunNeedsBeingsForTheTeam(t *testing.T) {
+	fc := testConfig()
+	fc.NPCs = nil
+	spec := testSpec(t)
+	_, err := Run(log.New(io.Discard, "", 0), spec, fc, nil)
+	assert.EqualError(t, err, "team on seed 1: a team of 2 but only 1 beings")
+}
+
+func TestRank(t *testing.T) {
+	results := Rank([]Match{
+		{Entry: "a", Total: 1, Scores: map[string]float64{"explore": 1}},
+		{Entry: "b", Total: 4, Scores: map[string]float64{"explore": 4}},
+		{Entry: "a", Total: 5, Scores: map[string]float64{"explore": 5}},
+		{Entry: "b", Total: 2, Scores: map[string]float64{"explore": 2}},
+		{Entry: "c", Total: 5, Scores: map[string]float64{"explore": 5}},
+	})
+	require.Len(t, results, 3)
+	assert.Equal(t, Result{Rank: 1, Entry: "c", Mean: 5, Rules: map[string]float64{"explore": 5}, Totals: []float64{5}}, results[0])
+	assert.Equal(t, "a", results[1].Entry, "Ties keep the order the entries played in")
+	assert.Equal(t, []float64{1, 5}, results[1].Totals)
+	assert.Equal(t, map[string]float64{"explore": 3}, results[1].Rules)
+	assert.Equal(t, 2, results[2].Rank, "Entries with the same mean share a rank")
+}
+
+func TestWriteTable(t *testing.T) {
+	spec := Spec{Seeds: []int64{1, 2}, Scoring: map[string]float64{"explore": 1, "torch": 2}}
+	var buf bytes.Buffer
+	require.NoError(t, WriteTable(&buf, spec, []Result{
+		{Rank: 1, Entry: "seeker", Mean: 12, Rules: map[string]float64{"explore": 4, "torch": 4}, Totals: []float64{10, 14}},
+	}))
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 2)
+	assert.Equal(t, []string{"rank", "entry", "mean", "explore", "x1", "torch", "x2", "seed", "1", "seed", "2"}, strings.Fields(lines[0]))
+	assert.Equal(t, []string{"1", "seeker", "12.0", "4.0", "4.0", "10.0", "14.0"}, strings.Fields(lines[1]))
+}
+
+func TestExampleTournamentIsValid(t *testing.T) {
+	spec, err := LoadSpec("../../bots/tournament.json")
+	require.NoError(t, err)
+	_, err = world.LoadConfig(filepath.Join("../..", spec.Config))
+	assert.NoError(t, err, "The tournament should use a valid config")
+}
@@ -0,0 +1,246 @@
+package vm
+
+import (
+	"bufio"
+	"fmt"
+	"gobotworld/src/world/object"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+type opcode int
+
+const (
+	opSet opcode = iota
+	opAdd
+	opSub
+	opMul
+	opDiv
+	opMod
+	opJmp
+	opJeq
+	opJne
+	opJlt
+	opJgt
+	opLook
+	opLight
+	opTime
+	opDay
+	opMove
+	opTurn
+	opRotate
+	opTake
+	opPlace
+	opCarry
+	opWait
+)
+
+// operandKind is what an instruction takes in each position.
+type operandKind int
+
+const (
+	register operandKind = iota
+	value
+	label
+)
+
+type spec struct {
+	op       opcode
+	operands []operandKind
+	// optional is how many of the operands at the end can be left out
+	optional int
+}
+
+var instructions = map[string]spec{
+	"set":   {op: opSet, operands: []operandKind{register, value}},
+	"add":   {op: opAdd, operands: []operandKind{register, value}},
+	"sub":   {op: opSub, operands: []operandKind{register, value}},
+	"mul":   {op: opMul, operands: []operandKind{register, value}},
+	"div":   {op: opDiv, operands: []operandKind{register, value}},
+	"mod":   {op: opMod, operands: []operandKind{register, value}},
+	"jmp":   {op: opJmp, operands: []operandKind{label}},
+	"jeq":   {op: opJeq, operands: []operandKind{value, value, label}},
+	"jne":   {op: opJne, operands: []operandKind{value, value, label}},
+	"jlt":   {op: opJlt, operands: []operandKind{value, value, label}},
+	"jgt":   {op: opJgt, operands: []operandKind{value, value, label}},
+	"look":  {op: opLook, operands: []operandKind{register, value}, optional: 1},
+	"light": {op: opLight, operands: []operandKind{register}},
+	"time":  {op: opTime, operands: []operandKind{register}},
+	"day":   {op: opDay, operands: []operandKind{register}},
+	"move":  {op: opMove},
+	"turn":  {op: opTurn, operands: []operandKind{value}},
+	"take":  {op: opTake},
+	"place": {op: opPlace},
+	"carry": {op: opCarry, operands: []operandKind{register}},
+	"wait":  {op: opWait},
+}
+
+// turns are the quarter turns clockwise turn takes instead of a direction.
+var turns = map[string]int{
+	"right":  1,
+	"around": 2,
+	"left":   3,
+}
+
+// operand is a register number or a value. Labels are resolved to the index
+// of the instruction they name.
+type operand struct {
+	register bool
+	value    int
+}
+
+type instruction struct {
+	op       opcode
+	operands []operand
+	line     int
+	text     string
+}
+
+// Program is assembled bot code, ready to run on a Machine.
+type Program struct {
+	Name         string
+	instructions []instruction
+}
+
+// Len is the number of instructions in the program.
+func (p *Program) Len() int {
+	return len(p.instructions)
+}
+
+// constants are the names that can be used for values: the object types, as
+// look reports them, and the directions turn takes.
+func constants() map[string]int {
+	names := map[string]int{
+		"north": int(object.North),
+		"south": int(object.South),
+		"east":  int(object.East),
+		"west":  int(object.West),
+		"none":  nothing,
+	}
+	for t := object.ObjectType(0); t.String() != "unknown"; t++ {
+		names[t.String()] = int(t)
+	}
+	return names
+}
+
+// Assemble reads a program written one instruction per line. Anything after a
+// ';' is a comment and a line ending in ':' is a label to jump to.
+//
+//	loop:
+//	    look r0
+//	    jeq r0 obstacle blocked ; a wall ahead
+//	    move
+//	    jmp loop
+//	blocked:
+//	    turn right
+//	    jmp loop
+func Assemble(name string, r io.Reader) (*Program, error) {
+	type pending struct {
+		instruction int
+		operand     int
+		label       string
+		line        int
+	}
+	program := &Program{Name: name}
+	labels := map[string]int{}
+	var fixups []pending
+	names := constants()
+
+	scanner := bufio.NewScanner(r)
+	for lineNo := 1; scanner.Scan(); lineNo++ {
+		text := scanner.Text()
+		if i := strings.IndexByte(text, ';'); i >= 0 {
+			text = text[:i]
+		}
+		fields := strings.Fields(strings.ToLower(text))
+		if len(fields) == 0 {
+			continue
+		}
+		if l, ok := strings.CutSuffix(fields[0], ":"); ok && len(fields) == 1 {
+			if _, dup := labels[l]; dup {
+				return nil, fmt.Errorf("%s:%d: label %s is defined twice", name, lineNo, l)
+			}
+			labels[l] = len(program.instructions)
+			continue
+		}
+
+		s, ok := instructions[fields[0]]
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: unknown instruction %q", name, lineNo, fields[0])
+		}
+		args := fields[1:]
+		if len(args) > len(s.operands) || len(args) < len(s.operands)-s.optional {
+			return nil, fmt.Errorf("%s:%d: %s takes %d operands, got %d", name, lineNo, fields[0], len(s.operands), len(args))
+		}
+
+		in := instruction{op: s.op, line: lineNo, text: strings.Join(fields, " ")}
+		for i, arg := range args {
+			var o operand
+			switch s.operands[i] {
+			case register:
+				n, ok := parseRegister(arg)
+				if !ok {
+					return nil, fmt.Errorf("%s:%d: %s needs a register, got %q", name, lineNo, fields[0], arg)
+				}
+				o = operand{register: true, value: n}
+			case label:
+				fixups = append(fixups, pending{instruction: len(program.instructions), operand: i, label: arg, line: lineNo})
+			case value:
+				if n, ok := parseRegister(arg); ok {
+					o = operand{register: true, value: n}
+				} else if n, ok := turns[arg]; ok && s.op == opTurn {
+					in.op = opRotate
+					o = operand{value: n}
+				} else if n, ok := names[arg]; ok {
+					o = operand{value: n}
+				} else if n, err := strconv.Atoi(arg); err == nil {
+					o = operand{value: n}
+				} else {
+					return nil, fmt.Errorf("%s:%d: %q is not a register, number or name", name, lineNo, arg)
+				}
+			}
+			in.operands = append(in.operands, o)
+		}
+		program.instructions = append(program.instructions, in)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("reading %s: %w", name, err)
+	}
+
+	for _, f := range fixups {
+		target, ok := labels[f.label]
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: no label %s", name, f.line, f.label)
+		}
+		program.instructions[f.instruction].operands[f.operand] = operand{value: target}
+	}
+	if len(program.instructions) == 0 {
+		return nil, fmt.Errorf("%s: program has no instructions", name)
+	}
+	return program, nil
+}
+
+func parseRegister(arg string) (int, bool) {
+	digits, ok := strings.CutPrefix(arg, "r")
+	if !ok {
+		return 0, false
+	}
+	n, err := strconv.Atoi(digits)
+	if err != nil || n < 0 || n >= Registers {
+		return 0, false
+	}
+	return n, true
+}
+
+// LoadFile assembles the program in a file, named after the file.
+func LoadFile(path string) (*Program, error) {
+	in, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer in.Close()
+	return Assemble(filepath.Base(path), in)
+}
@@ -0,0 +1,65 @@
+package vm
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func assemble(t *testing.T, lines ...string) *Program {
+	p, err := Assemble("test", strings.NewReader(strings.Join(lines, "\n")))
+	require.NoError(t, err)
+	return p
+}
+
+func TestAssemble(t *testing.T) {
+	p := assemble(t,
+		"; a comment on its own",
+		"start:",
+		"  set r0 torch ; names are values",
+		"  add r0 r1",
+		"  look r2 3",
+		"  jeq r0 -1 start",
+		"  turn left",
+		"  turn north",
+		"  move",
+	)
+	require.Equal(t, 7, p.Len())
+	assert.Equal(t, opSet, p.instructions[0].op)
+	assert.Equal(t, []operand{{register: true, value: 0}, {value: 6}}, p.instructions[0].operands)
+	assert.Equal(t, []operand{{register: true, value: 0}, {register: true, value: 1}}, p.instructions[1].operands)
+	assert.Equal(t, operand{value: 0}, p.instructions[3].operands[2], "Labels are the instruction they name")
+	assert.Equal(t, opRotate, p.instructions[4].op)
+	assert.Equal(t, opTurn, p.instructions[5].op)
+	assert.Equal(t, 3, p.instructions[0].line, "Lines are counted from one, comments included")
+}
+
+func TestAssembleErrors(t *testing.T) {
+	for _, tc := range []struct {
+		program string
+		err     string
+	}{
+		{"jump r0", `test:1: unknown instruction "jump"`},
+		{"set r0", "test:1: set takes 2 operands, got 1"},
+		{"set 3 r0", `test:1: set needs a register, got "3"`},
+		{"set r9 1", `test:1: set needs a register, got "r9"`},
+		{"add r0 lots", `test:1: "lots" is not a register, number or name`},
+		{"move\njmp nowhere", "test:2: no label nowhere"},
+		{"a:\na:\nmove", "test:2: label a is defined twice"},
+		{"; nothing", "test: program has no instructions"},
+	} {
+		_, err := Assemble("test", strings.NewReader(tc.program))
+		assert.EqualError(t, err, tc.err)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	p, err := LoadFile("../../bots/explorer.asm")
+	require.NoError(t, err)
+	assert.Equal(t, "explorer.asm", p.Name)
+
+	_, err = LoadFile("missing.asm")
+	assert.Error(t, err)
+}
@@ -0,0 +1,234 @@
+// Package vm runs bots written in a small assembly language inside the game.
+// Every bot gets the same number of instructions each tick, however fast the
+// machine running the world, which keeps competitions between bots fair.
+package vm
+
+import (
+	"errors"
+	"fmt"
+	"gobotworld/src/world"
+	"gobotworld/src/world/object"
+	"image"
+	"log"
+	"slices"
+	"strings"
+)
+
+const (
+	// Registers is how many registers a machine has, r0 to r7.
+	Registers = 8
+	// DefaultBudget is how many instructions a bot may run each tick.
+	DefaultBudget = 64
+)
+
+// nothing is what look sees off the map or past how far a being can see.
+const nothing = -1
+
+var errDivideByZero = errors.New("divide by zero")
+
+// clockwise is the order turn counts quarter turns in.
+var clockwise = []object.Direction{object.North, object.East, object.South, object.West}
+
+var deltas = map[object.Direction]image.Point{
+	object.North: {X: 0, Y: -1},
+	object.East:  {X: 1, Y: 0},
+	object.South: {X: 0, Y: 1},
+	object.West:  {X: -1, Y: 0},
+}
+
+// Machine is a world.Controller that runs a program. Each tick it carries on
+// from where it stopped until it runs move, turn or wait, which is what the
+// being does that tick. If the budget runs out first the being waits and the
+// program carries on from there next tick. Running off the end of the program
+// starts it again from the top. A machine that hits an error stops for good.
+type Machine struct {
+	Name      string
+	Budget    int
+	Registers [Registers]int
+	// PC is the index of the next instruction to run.
+	PC int
+	// Ran is how many instructions ran on the last tick.
+	Ran int
+	Err error
+
+	program *Program
+	logger  *log.Logger
+}
+
+func New(logger *log.Logger, program *Program, budget int) *Machine {
+	return &Machine{Name: program.Name, Budget: budget, program: program, logger: logger}
+}
+
+// Act runs the program until it takes an action or the budget runs out.
+func (m *Machine) Act(obs world.Observation) world.Action {
+	m.Ran = 0
+	if m.Err != nil {
+		return world.WaitAction(nil)
+	}
+	for m.Ran < m.Budget {
+		// Jumps to a label after the last instruction run off the end too
+		m.PC %= len(m.program.instructions)
+		in := m.program.instructions[m.PC]
+		m.PC = (m.PC + 1) % len(m.program.instructions)
+		m.Ran++
+		a, acted, err := m.step(in, obs)
+		if err != nil {
+			m.Err = fmt.Errorf("%s:%d: %s: %w", m.program.Name, in.line, in.text, err)
+			m.logger.Printf("Bot %s stopped: %v", m.Name, m.Err)
+			return world.WaitAction(nil)
+		}
+		if acted {
+			return a
+		}
+	}
+	return world.WaitAction(nil)
+}
+
+func (m *Machine) get(o operand) int {
+	if o.register {
+		return m.Registers[o.value]
+	}
+	return o.value
+}
+
+// step runs a single instruction, returning the action if it was an actuator.
+func (m *Machine) step(in instruction, obs world.Observation) (world.Action, bool, error) {
+	ops := in.operands
+	jump := func(taken bool) {
+		if taken {
+			m.PC = ops[2].value
+		}
+	}
+
+	switch in.op {
+	case opSet:
+		m.Registers[ops[0].value] = m.get(ops[1])
+	case opAdd:
+		m.Registers[ops[0].value] += m.get(ops[1])
+	case opSub:
+		m.Registers[ops[0].value] -= m.get(ops[1])
+	case opMul:
+		m.Registers[ops[0].value] *= m.get(ops[1])
+	case opDiv, opMod:
+		d := m.get(ops[1])
+		if d == 0 {
+			return world.Action{}, false, errDivideByZero
+		}
+		if in.op == opDiv {
+			m.Registers[ops[0].value] /= d
+		} else {
+			m.Registers[ops[0].value] %= d
+		}
+	case opJmp:
+		m.PC = ops[0].value
+	case opJeq:
+		jump(m.get(ops[0]) == m.get(ops[1]))
+	case opJne:
+		jump(m.get(ops[0]) != m.get(ops[1]))
+	case opJlt:
+		jump(m.get(ops[0]) < m.get(ops[1]))
+	case opJgt:
+		jump(m.get(ops[0]) > m.get(ops[1]))
+	case opLook:
+		distance := 1
+		if len(ops) > 1 {
+			distance = m.get(ops[1])
+		}
+		m.Registers[ops[0].value] = look(obs, distance)
+	case opLight:
+		m.Registers[ops[0].value] = obs.Light
+	case opTime:
+		m.Registers[ops[0].value] = obs.Tick
+	case opDay:
+		m.Registers[ops[0].value] = 0
+		if obs.Cycle == object.DayTime {
+			m.Registers[ops[0].value] = 1
+		}
+	case opMove:
+		return world.MoveAction(nil, obs.Facing), true, nil
+	case opTurn:
+		d := object.Direction(m.get(ops[0]))
+		if _, ok := deltas[d]; !ok {
+			return world.Action{}, false, fmt.Errorf("%d is not a direction", d)
+		}
+		return world.FaceAction(nil, d), true, nil
+	case opRotate:
+		i := slices.Index(clockwise, obs.Facing)
+		if i < 0 {
+			i = 0
+		}
+		return world.FaceAction(nil, clockwise[(i+ops[0].value)%len(clockwise)]), true, nil
+	case opTake:
+		return world.TakeAction(nil, obs.Facing), true, nil
+	case opPlace:
+		return world.PlaceAction(nil, obs.Facing), true, nil
+	case opCarry:
+		m.Registers[ops[0].value] = 0
+		if obs.Carrying {
+			m.Registers[ops[0].value] = 1
+		}
+	case opWait:
+		return world.WaitAction(nil), true, nil
+	}
+	return world.Action{}, false, nil
+}
+
+// look returns the type of the top thing distance cells ahead, the being if
+// there is one.
+func look(obs world.Observation, distance int) int {
+	if distance < 1 {
+		return nothing
+	}
+	p := obs.Location.Add(deltas[obs.Facing].Mul(distance))
+	cell, ok := obs.Cell(p)
+	if !ok || len(cell.Things) == 0 {
+		return nothing
+	}
+	return int(cell.Things[len(cell.Things)-1])
+}
+
+// Inspect describes the state of the machine in a few short lines.
+func (m *Machine) Inspect() []string {
+	lines := []string{fmt.Sprintf("%s pc %d", m.Name, m.PC)}
+	if m.Err != nil {
+		lines = append(lines, "stopped")
+	} else {
+		lines = append(lines, fmt.Sprintf("ran %d/%d", m.Ran, m.Budget))
+	}
+	for i := 0; i < Registers; i += 2 {
+		lines = append(lines, fmt.Sprintf("r%d %d r%d %d", i, m.Registers[i], i+1, m.Registers[i+1]))
+	}
+	return lines
+}
+
+// Attach loads a program for each path and hands a machine running it to a
+// being, in the order the beings are given.
+func Attach(logger *log.Logger, w world.World, beings []*object.Character, budget int, paths []string) ([]*Machine, error) {
+	if len(paths) > len(beings) {
+		return nil, fmt.Errorf("%d programs but only %d beings for them", len(paths), len(beings))
+	}
+	var machines []*Machine
+	for i, path := range paths {
+		program, err := LoadFile(path)
+		if err != nil {
+			return nil, err
+		}
+		m := New(logger, program, budget)
+		logger.Printf("Bot %s controls being %d", m.Name, beings[i].Ident().Index)
+		w.SetController(beings[i], m)
+		machines = append(machines, m)
+	}
+	return machines, nil
+}
+
+// Paths collects the programs of a flag that can be given more than once.
+type Paths []string
+
+func (p *Paths) String() string {
+	return strings.Join(*p, ", ")
+}
+
+func (p *Paths) Set(path string) error {
+	*p = append(*p, path)
+	return nil
+}
@@ -0,0 +1,199 @@
+package vm
+
+import (
+	"bytes"
+	"gobotworld/src/sim"
+	"gobotworld/src/world"
+	"gobotworld/src/world/object"
+	"image"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// observation is a being at (5, 5) facing east, with a torch two cells ahead
+// and nothing else about.
+func observation() world.Observation {
+	obs := world.Observation{
+		Tick:     42,
+		Cycle:    object.NightTime,
+		Location: image.Point{X: 5, Y: 5},
+		Facing:   object.East,
+		Light:    3,
+		Radius:   world.ObservationRadius,
+	}
+	for y := -obs.Radius; y <= obs.Radius; y++ {
+		for x := -obs.Radius; x <= obs.Radius; x++ {
+			p := obs.Location.Add(image.Point{X: x, Y: y})
+			things := []object.ObjectType{object.Dirt1Type}
+			if p == (image.Point{X: 7, Y: 5}) {
+				things = append(things, object.TorchType)
+			}
+			obs.Cells = append(obs.Cells, world.Cell{Point: p, Things: things, Passable: true})
+		}
+	}
+	return obs
+}
+
+func machine(t *testing.T, lines ...string) *Machine {
+	return New(log.New(io.Discard, "", 0), assemble(t, lines...), DefaultBudget)
+}
+
+func TestArithmetic(t *testing.T) {
+	m := machine(t,
+		"set r0 7",
+		"add r0 5",
+		"sub r0 2",
+		"mul r0 3",
+		"set r1 r0",
+		"div r1 4",
+		"set r2 r0",
+		"mod r2 4",
+		"wait",
+	)
+	assert.Equal(t, world.WaitAction(nil), m.Act(observation()))
+	assert.Equal(t, [Registers]int{30, 7, 2}, m.Registers)
+	assert.Equal(t, 9, m.Ran)
+}
+
+func TestJumps(t *testing.T) {
+	m := machine(t,
+		"  set r0 0",
+		"loop:",
+		"  add r0 1",
+		"  jlt r0 5 loop",
+		"  jgt r0 5 never",
+		"  jne r0 5 never",
+		"  jeq r0 5 done",
+		"never:",
+		"  set r1 1",
+		"done:",
+		"  wait",
+	)
+	m.Act(observation())
+	assert.Equal(t, 5, m.Registers[0])
+	assert.Equal(t, 0, m.Registers[1])
+}
+
+func TestJumpToTheEnd(t *testing.T) {
+	m := machine(t,
+		"  add r0 1",
+		"  jlt r0 3 end",
+		"  wait",
+		"end:",
+	)
+	assert.Equal(t, world.WaitAction(nil), m.Act(observation()), "Jumping past the last instruction starts again from the top")
+	assert.Equal(t, 3, m.Registers[0])
+	assert.NoError(t, m.Err)
+
+	m = machine(t, "jmp end", "end:")
+	assert.Equal(t, world.WaitAction(nil), m.Act(observation()), "Programs that never act use up their budget")
+	assert.Equal(t, DefaultBudget, m.Ran)
+}
+
+func TestSensors(t *testing.T) {
+	m := machine(t,
+		"look r0",
+		"look r1 2",
+		"look r2 99",
+		"light r3",
+		"time r4",
+		"day r5",
+		"wait",
+	)
+	m.Act(observation())
+	assert.Equal(t, [Registers]int{int(object.Dirt1Type), int(object.TorchType), nothing, 3, 42, 0}, m.Registers)
+}
+
+func TestActuators(t *testing.T) {
+	m := machine(t,
+		"move",
+		"turn right",
+		"turn around",
+		"turn left",
+		"set r0 north",
+		"turn r0",
+	)
+	obs := observation()
+	assert.Equal(t, world.MoveAction(nil, object.East), m.Act(obs))
+	assert.Equal(t, world.FaceAction(nil, object.South), m.Act(obs))
+	assert.Equal(t, world.FaceAction(nil, object.West), m.Act(obs))
+	assert.Equal(t, world.FaceAction(nil, object.North), m.Act(obs))
+	assert.Equal(t, world.FaceAction(nil, object.North), m.Act(obs))
+	assert.Equal(t, 2, m.Ran, "set doesn't end the tick")
+	assert.Equal(t, world.MoveAction(nil, object.East), m.Act(obs), "Programs start again from the top")
+}
+
+func TestLights(t *testing.T) {
+	m := machine(t,
+		"carry r0",
+		"take",
+		"carry r1",
+		"place",
+	)
+	obs := observation()
+	assert.Equal(t, world.TakeAction(nil, object.East), m.Act(obs), "Lights are taken from ahead")
+	assert.Equal(t, 0, m.Registers[0])
+
+	obs.Carrying = true
+	assert.Equal(t, world.PlaceAction(nil, object.East), m.Act(obs), "And placed ahead")
+	assert.Equal(t, 1, m.Registers[1])
+}
+
+func TestBudget(t *testing.T) {
+	m := machine(t,
+		"loop:",
+		"  add r0 1",
+		"  jlt r0 100 loop",
+		"  move",
+	)
+	m.Budget = 10
+	assert.Equal(t, world.WaitAction(nil), m.Act(observation()), "Running out of budget waits")
+	assert.Equal(t, 10, m.Ran)
+	assert.Equal(t, 5, m.Registers[0])
+
+	for range 19 {
+		assert.Equal(t, world.WaitAction(nil), m.Act(observation()))
+	}
+	assert.Equal(t, world.MoveAction(nil, object.East), m.Act(observation()), "The program carries on where it stopped")
+}
+
+func TestErrorsStopTheMachine(t *testing.T) {
+	var out bytes.Buffer
+	m := New(log.New(&out, "", 0), assemble(t, "set r0 1", "div r0 r1", "move"), DefaultBudget)
+	assert.Equal(t, world.WaitAction(nil), m.Act(observation()))
+	assert.EqualError(t, m.Err, "test:2: div r0 r1: divide by zero")
+	assert.Contains(t, out.String(), "divide by zero")
+	assert.Equal(t, world.WaitAction(nil), m.Act(observation()), "Stopped machines wait for good")
+	assert.Contains(t, m.Inspect(), "stopped")
+
+	m = machine(t, "turn 9")
+	m.Act(observation())
+	assert.EqualError(t, m.Err, "test:1: turn 9: 9 is not a direction")
+}
+
+func TestInspect(t *testing.T) {
+	m := machine(t, "set r3 12", "wait")
+	m.Act(observation())
+	assert.Equal(t, []string{"test pc 0", "ran 2/64", "r0 0 r1 0", "r2 0 r3 12", "r4 0 r5 0", "r6 0 r7 0"}, m.Inspect())
+}
+
+func TestAttach(t *testing.T) {
+	logger := log.New(io.Discard, "", 0)
+	w := world.InitWorld(logger, 20, 20, world.EmptyConfig().WithSeed(1).WithNPCs())
+
+	_, err := Attach(logger, w, w.Roster(), DefaultBudget, []string{"../../bots/explorer.asm", "../../bots/explorer.asm"})
+	assert.Error(t, err, "There is only the player")
+
+	machines, err := Attach(logger, w, w.Roster(), DefaultBudget, []string{"../../bots/explorer.asm"})
+	require.NoError(t, err)
+	c, _ := w.Controller(w.Player)
+	assert.Same(t, machines[0], c)
+
+	start := *w.Player.Location
+	sim.Step(w)
+	assert.Equal(t, start.Add(image.Point{Y: -1}), *w.Player.Location, "The explorer should head north")
+}
@@ -0,0 +1,124 @@
+// Package provides a queue of actions so the world can be driven from several goroutines.
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"sync"
+)
+
+type ActionKind int
+
+const (
+	ActionWait ActionKind = iota
+	ActionMove
+	ActionFace
+	ActionPlace
+	ActionTake
+)
+
+// String method for ActionKind
+func (k ActionKind) String() string {
+	switch k {
+	case ActionWait:
+		return "Wait"
+	case ActionMove:
+		return "Move"
+	case ActionFace:
+		return "Face"
+	case ActionPlace:
+		return "Place"
+	case ActionTake:
+		return "Take"
+	default:
+		return "Unknown"
+	}
+}
+
+// Action is something a being intends to do. Actions are queued with
+// World.Enqueue and applied in order by the next World.Tick.
+type Action struct {
+	Being     *object.Character
+	Kind      ActionKind
+	Direction object.Direction
+}
+
+func MoveAction(being *object.Character, direction object.Direction) Action {
+	return Action{Being: being, Kind: ActionMove, Direction: direction}
+}
+
+func FaceAction(being *object.Character, direction object.Direction) Action {
+	return Action{Being: being, Kind: ActionFace, Direction: direction}
+}
+
+// PlaceAction puts the light the being carries down in direction.
+func PlaceAction(being *object.Character, direction object.Direction) Action {
+	return Action{Being: being, Kind: ActionPlace, Direction: direction}
+}
+
+// TakeAction picks up the light next to the being in direction.
+func TakeAction(being *object.Character, direction object.Direction) Action {
+	return Action{Being: being, Kind: ActionTake, Direction: direction}
+}
+
+func WaitAction(being *object.Character) Action {
+	return Action{Being: being, Kind: ActionWait}
+}
+
+type actionQueue struct {
+	mu        sync.Mutex
+	pending   []Action
+	observers []func(tick int, a Action)
+}
+
+func (q *actionQueue) push(a Action) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.pending = append(q.pending, a)
+}
+
+// drain removes and returns everything queued so far, along with the observers
+// that should see it applied.
+func (q *actionQueue) drain() ([]Action, []func(tick int, a Action)) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	pending := q.pending
+	q.pending = nil
+	return pending, q.observers
+}
+
+// Enqueue records an action to be applied on the next Tick. It is safe to call
+// from any goroutine, unlike Move which must only be called by the goroutine
+// that ticks the world.
+func (world World) Enqueue(a Action) {
+	world.actions.push(a)
+}
+
+// OnAction registers fn to be called from Tick with every queued action as it
+// is applied, along with the tick it was applied on.
+func (world World) OnAction(fn func(tick int, a Action)) {
+	world.actions.mu.Lock()
+	defer world.actions.mu.Unlock()
+	world.actions.observers = append(world.actions.observers, fn)
+}
+
+// apply carries out a single action and reports whether it succeeded.
+func (world World) apply(a Action) bool {
+	// The being may have despawned since the action was queued
+	if _, ok := world.Beings[a.Being]; !ok {
+		return false
+	}
+	switch a.Kind {
+	case ActionWait:
+		return true
+	case ActionMove:
+		return world.Move(a.Being, a.Direction)
+	case ActionFace:
+		a.Being.Direction = a.Direction
+		return true
+	case ActionPlace:
+		return world.Place(a.Being, a.Direction)
+	case ActionTake:
+		return world.Take(a.Being, a.Direction)
+	}
+	return false
+}
@@ -0,0 +1,94 @@
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"image"
+	"io"
+	"log"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEnqueueAppliesOnTick(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	worldInstance := EmptyWorld(logger)
+	player := worldInstance.Player
+	start := *player.Location
+
+	worldInstance.Enqueue(MoveAction(player, object.East))
+	assert.Equal(t, start, *player.Location, "Queued actions should wait for the next tick")
+
+	worldInstance.Tick()
+	assert.Equal(t, image.Point{X: start.X + 1, Y: start.Y}, *player.Location, "Move should be applied on tick")
+}
+
+func TestActionsApplyInOrder(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	worldInstance := EmptyWorld(logger)
+	player := worldInstance.Player
+	start := *player.Location
+
+	worldInstance.Enqueue(MoveAction(player, object.East))
+	worldInstance.Enqueue(MoveAction(player, object.South))
+	worldInstance.Enqueue(WaitAction(player))
+	worldInstance.Enqueue(FaceAction(player, object.West))
+	worldInstance.Tick()
+
+	assert.Equal(t, image.Point{X: start.X + 1, Y: start.Y + 1}, *player.Location, "Both moves should be applied")
+	assert.Equal(t, object.West, player.Direction, "Face should turn without moving")
+}
+
+func TestEnqueueFromManyGoroutines(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	worldInstance := EmptyWorld(logger)
+	player := worldInstance.Player
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				worldInstance.Enqueue(FaceAction(player, object.North))
+			}
+		}()
+	}
+	for i := 0; i < 100; i++ {
+		worldInstance.Tick()
+		worldInstance.NpcMove()
+	}
+	wg.Wait()
+	worldInstance.Tick()
+
+	pending, _ := worldInstance.actions.drain()
+	assert.Empty(t, pending, "Every queued action should have been applied")
+}
+
+func TestActionKindString(t *testing.T) {
+	assert.Equal(t, "Move", ActionMove.String())
+	assert.Equal(t, "Face", ActionFace.String())
+	assert.Equal(t, "Wait", ActionWait.String())
+	assert.Equal(t, "Unknown", ActionKind(99).String())
+}
+
+func TestOnActionSeesAppliedActions(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	worldInstance := EmptyWorld(logger)
+	player := worldInstance.Player
+
+	var ticks []int
+	worldInstance.OnAction(func(tick int, a Action) {
+		ticks = append(ticks, tick)
+		assert.Equal(t, player, a.Being, "Observer should see the queued action")
+	})
+
+	worldInstance.Enqueue(MoveAction(player, object.East))
+	worldInstance.Tick()
+	worldInstance.Tick()
+	worldInstance.Enqueue(WaitAction(player))
+	worldInstance.Tick()
+
+	assert.Equal(t, []int{0, 2}, ticks, "Observer should be told the tick each action was applied on")
+}
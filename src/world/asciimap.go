@@ -0,0 +1,298 @@
+// Package provides loading hand drawn maps from plain text.
+package world
+
+import (
+	"bufio"
+	"fmt"
+	"gobotworld/src/world/object"
+	"image"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// DefaultLegend maps glyphs to object types. It uses the same glyphs the
+// terminal draws so a screenshot of the game can be pasted in as a map.
+var DefaultLegend = map[rune]object.ObjectType{
+	' ': object.Dirt1Type,
+	'.': object.Dirt2Type,
+	'o': object.RockType,
+	'@': object.ObstacleType,
+	'M': object.PlayerType,
+	'E': object.EnemyType,
+	'^': object.TorchType,
+	'c': object.CritterType,
+}
+
+// ASCIIMap is a map read from text along with the spawn points drawn on it.
+// Characters stand on Dirt1 in the generated map, and each torch drawn becomes
+// a light there.
+type ASCIIMap struct {
+	Geography Map
+	Lights    []*image.Point
+	// Torches holds the settings the legend gave the torches drawn at each
+	// point, the rest burn like any torch of the config.
+	Torches map[image.Point]LightConfig
+	Player  *image.Point
+	NPCs    []NPCSpawn
+}
+
+// ReadASCIIMap reads a map drawn with the DefaultLegend glyphs. The map can be
+// preceded by a legend header that adds or overrides glyphs, one per line as
+// "<glyph> = <type>", ended by a line of "---". Torch glyphs can be followed by
+// how they burn, any of radius, colour, intensity and flicker as key=value
+// with lists separated by commas. Lines starting with "//" in the header are
+// comments. Short rows are padded with Dirt1 since editors like to trim
+// trailing spaces.
+//
+//	# = obstacle
+//	* = torch radius=2 colour=120,160,255 flicker=100,80,90
+//	---
+//	#######
+//	#M  ^*#
+//	#######
+func ReadASCIIMap(r io.Reader) (ASCIIMap, error) {
+	var lines []string
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		lines = append(lines, strings.TrimRight(scanner.Text(), "\r"))
+	}
+	if err := scanner.Err(); err != nil {
+		return ASCIIMap{}, err
+	}
+
+	legend := map[rune]object.ObjectType{}
+	for glyph, objType := range DefaultLegend {
+		legend[glyph] = objType
+	}
+
+	torches := map[rune]LightConfig{}
+
+	// offset is the number of lines before the first row of the map
+	offset := 0
+	for i, line := range lines {
+		if line == "---" {
+			if err := parseLegend(lines[:i], legend, torches); err != nil {
+				return ASCIIMap{}, err
+			}
+			offset = i + 1
+			break
+		}
+	}
+	rows := lines[offset:]
+	// Drop trailing blank lines left by editors
+	for len(rows) > 0 && rows[len(rows)-1] == "" {
+		rows = rows[:len(rows)-1]
+	}
+	if len(rows) == 0 {
+		return ASCIIMap{}, fmt.Errorf("map has no rows")
+	}
+
+	width := 0
+	for _, row := range rows {
+		width = max(width, utf8.RuneCountInString(row))
+	}
+
+	am := ASCIIMap{Geography: make(Map, len(rows))}
+	for y, row := range rows {
+		am.Geography[y] = make([]object.ThingList, width)
+		x := 0
+		for _, glyph := range row {
+			objType, ok := legend[glyph]
+			if !ok {
+				return ASCIIMap{}, fmt.Errorf("line %d column %d: unknown glyph %q", y+offset+1, x+1, glyph)
+			}
+			p := image.Point{X: x, Y: y}
+			switch objType {
+			case object.PlayerType:
+				if am.Player != nil {
+					return ASCIIMap{}, fmt.Errorf("line %d column %d: second player, the first is at %v", y+offset+1, x+1, *am.Player)
+				}
+				am.Player = &p
+				objType = object.Dirt1Type
+			case object.EnemyType, object.CritterType:
+				am.NPCs = append(am.NPCs, NPCSpawn{Type: objType, Location: &p})
+				objType = object.Dirt1Type
+			case object.TorchType:
+				am.Lights = append(am.Lights, &p)
+				if lc, ok := torches[glyph]; ok {
+					if am.Torches == nil {
+						am.Torches = map[image.Point]LightConfig{}
+					}
+					am.Torches[p] = lc
+				}
+			}
+			am.Geography[y][x] = object.ThingList{terrainThing(objType)}
+			x++
+		}
+		for ; x < width; x++ {
+			am.Geography[y][x] = object.ThingList{terrainThing(object.Dirt1Type)}
+		}
+	}
+	return am, nil
+}
+
+func parseLegend(header []string, legend map[rune]object.ObjectType, torches map[rune]LightConfig) error {
+	for i, line := range header {
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(line, "//") {
+			continue
+		}
+		glyph, entry, ok := strings.Cut(line, "=")
+		glyph = strings.TrimSpace(glyph)
+		fields := strings.Fields(entry)
+		if !ok || utf8.RuneCountInString(glyph) != 1 || len(fields) == 0 {
+			return fmt.Errorf("line %d: legend entries look like \"# = obstacle\", got %q", i+1, line)
+		}
+		objType, err := object.ParseObjectType(fields[0])
+		if err != nil {
+			return fmt.Errorf("line %d: %w", i+1, err)
+		}
+		r, _ := utf8.DecodeRuneInString(glyph)
+		legend[r] = objType
+		delete(torches, r)
+		if len(fields) == 1 {
+			continue
+		}
+		if objType != object.TorchType {
+			return fmt.Errorf("line %d: only torches have settings, %s doesn't", i+1, fields[0])
+		}
+		lc, err := parseLight(fields[1:])
+		if err != nil {
+			return fmt.Errorf("line %d: %w", i+1, err)
+		}
+		torches[r] = lc
+	}
+	return nil
+}
+
+// parseLight reads the key=value settings of a torch in a legend.
+func parseLight(settings []string) (LightConfig, error) {
+	var lc LightConfig
+	for _, setting := range settings {
+		key, value, ok := strings.Cut(setting, "=")
+		if !ok {
+			return LightConfig{}, fmt.Errorf("torch settings look like \"radius=2\", got %q", setting)
+		}
+		numbers, err := parseInts(value)
+		if err != nil {
+			return LightConfig{}, fmt.Errorf("%s: %w", key, err)
+		}
+		switch {
+		case key == "radius" && len(numbers) == 1:
+			lc.Radius = numbers[0]
+		case key == "intensity" && len(numbers) == 1:
+			lc.Intensity = numbers[0]
+		case key == "flicker":
+			lc.Flicker = numbers
+		case key == "colour" && len(numbers) == 3:
+			var colour [3]uint8
+			for i, n := range numbers {
+				if n < 0 || n > 255 {
+					return LightConfig{}, fmt.Errorf("colour: must be between 0 and 255, got %d", n)
+				}
+				colour[i] = uint8(n)
+			}
+			lc.Colour = &colour
+		case key == "colour":
+			return LightConfig{}, fmt.Errorf("colour: needs red, green and blue, got %q", value)
+		case key == "radius" || key == "intensity":
+			return LightConfig{}, fmt.Errorf("%s: needs one number, got %q", key, value)
+		default:
+			return LightConfig{}, fmt.Errorf("unknown torch setting %q", key)
+		}
+	}
+	var err error
+	lc.validate(func(field, format string, args ...any) {
+		if err == nil {
+			err = fmt.Errorf("%s: %s", field, fmt.Sprintf(format, args...))
+		}
+	})
+	return lc, err
+}
+
+// parseInts reads a list of numbers separated by commas.
+func parseInts(list string) ([]int, error) {
+	var numbers []int
+	for _, field := range strings.Split(list, ",") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", field)
+		}
+		numbers = append(numbers, n)
+	}
+	return numbers, nil
+}
+
+// terrainThing is the map object for a terrain type, only obstacles and
+// torches block the way.
+func terrainThing(objType object.ObjectType) object.Thing {
+	return object.NewObject(0, objType, objType != object.ObstacleType && objType != object.TorchType)
+}
+
+func LoadASCIIMap(path string) (ASCIIMap, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return ASCIIMap{}, err
+	}
+	defer file.Close()
+
+	am, err := ReadASCIIMap(file)
+	if err != nil {
+		return ASCIIMap{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return am, nil
+}
+
+func (am ASCIIMap) Height() int {
+	return am.Geography.Height()
+}
+
+func (am ASCIIMap) Width() int {
+	return am.Geography.Width()
+}
+
+// Generate returns a copy of the drawn map, the size asked for is ignored.
+func (am ASCIIMap) Generate(_, _ int, cfg Config) (Map, object.Lights) {
+	geography := make(Map, len(am.Geography))
+	for y, row := range am.Geography {
+		geography[y] = make([]object.ThingList, len(row))
+		for x, things := range row {
+			geography[y][x] = append(object.ThingList(nil), things...)
+		}
+	}
+	lights := make(object.Lights, 0, len(am.Lights))
+	for _, p := range am.Lights {
+		torch := cfg.torchLight()
+		if lc, ok := am.Torches[*p]; ok {
+			torch = lc.apply(torch)
+		}
+		light := torch.CopyAt(*p)
+		geography.SetLoc(*p, object.ThingList{light})
+		lights = append(lights, light)
+	}
+	return geography, lights
+}
+
+// Apply makes cfg build this map, with the player where it was drawn and the
+// NPCs drawn on it on top of the NPCs cfg already has.
+func (am ASCIIMap) Apply(cfg Config) Config {
+	cfg = cfg.WithGenerator(am)
+	if am.Player != nil {
+		cfg = cfg.WithPlayerSpawn(*am.Player)
+	}
+	npcs := append([]NPCSpawn(nil), cfg.npcs...)
+	for _, npc := range am.NPCs {
+		location := *npc.Location
+		npcs = append(npcs, NPCSpawn{Type: npc.Type, Location: &location})
+	}
+	return cfg.WithNPCs(npcs...)
+}
+
+// NewWorld creates a world from the map using the rest of cfg for everything
+// the map doesn't say.
+func (am ASCIIMap) NewWorld(logger *log.Logger, cfg Config) World {
+	return InitWorld(logger, am.Height(), am.Width(), am.Apply(cfg))
+}
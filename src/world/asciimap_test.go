@@ -0,0 +1,138 @@
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"image"
+	"image/color"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadASCIIMap(t *testing.T) {
+	am, err := ReadASCIIMap(strings.NewReader("" +
+		"@@@@@@\n" +
+		"@M .^@\n" +
+		"@ oEc\n" +
+		"@@@@@@\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, 4, am.Height())
+	assert.Equal(t, 6, am.Width())
+	assert.Equal(t, &image.Point{X: 1, Y: 1}, am.Player, "Player should be where M is drawn")
+	assert.Equal(t, []NPCSpawn{
+		{Type: object.EnemyType, Location: &image.Point{X: 3, Y: 2}},
+		{Type: object.CritterType, Location: &image.Point{X: 4, Y: 2}},
+	}, am.NPCs, "NPCs should be where E and c are drawn")
+	assert.Equal(t, []*image.Point{{X: 4, Y: 1}}, am.Lights, "Torches should be lights")
+
+	assert.Equal(t, object.ObstacleType, am.Geography.At(image.Point{X: 0, Y: 0}).Top().Ident().Type)
+	assert.Equal(t, object.Dirt1Type, am.Geography.At(image.Point{X: 1, Y: 1}).Top().Ident().Type, "Characters stand on dirt")
+	assert.Equal(t, object.Dirt2Type, am.Geography.At(image.Point{X: 3, Y: 1}).Top().Ident().Type)
+	assert.Equal(t, object.RockType, am.Geography.At(image.Point{X: 2, Y: 2}).Top().Ident().Type)
+	assert.Equal(t, object.Dirt1Type, am.Geography.At(image.Point{X: 5, Y: 2}).Top().Ident().Type, "Short rows should be padded")
+	assert.False(t, am.Geography.CanPass(image.Point{X: 4, Y: 1}, nil), "Torches block the way")
+}
+
+func TestReadASCIIMapLegend(t *testing.T) {
+	am, err := ReadASCIIMap(strings.NewReader("" +
+		"// walls\n" +
+		"# = obstacle\n" +
+		"~ = rock\n" +
+		"---\n" +
+		"###\n" +
+		"#~#\n" +
+		"###\n"))
+	require.NoError(t, err)
+
+	assert.Equal(t, object.ObstacleType, am.Geography.At(image.Point{X: 0, Y: 0}).Top().Ident().Type, "Legend should add glyphs")
+	assert.Equal(t, object.RockType, am.Geography.At(image.Point{X: 1, Y: 1}).Top().Ident().Type)
+}
+
+func TestReadASCIIMapTorches(t *testing.T) {
+	am, err := ReadASCIIMap(strings.NewReader("" +
+		"* = torch radius=2 colour=120,160,255 intensity=9 flicker=100,80\n" +
+		"---\n" +
+		"@@@@@@\n" +
+		"@^  *@\n" +
+		"@@@@@@\n"))
+	require.NoError(t, err)
+
+	geography, lights := am.Generate(0, 0, NewConfig().WithTorchRadius(5))
+	require.Len(t, lights, 2)
+	plain, blue := lights[0], lights[1]
+	assert.Equal(t, 5, plain.Radius, "Torches without settings burn like those of the config")
+	assert.Equal(t, object.TorchColour, plain.Colour)
+	assert.Equal(t, 2, blue.Radius, "Torches burn the way the legend says")
+	assert.Equal(t, color.RGBA{R: 120, G: 160, B: 255, A: 0xFF}, blue.Colour)
+	assert.Equal(t, 9, blue.Intensity)
+	assert.Equal(t, []int{100, 80}, blue.Flicker)
+	assert.Equal(t, object.ThingList{blue}, geography.At(image.Point{X: 4, Y: 1}))
+}
+
+func TestReadASCIIMapErrors(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		err   string
+	}{
+		{"unknown glyph", "@@@\n@x@\n", "line 2 column 2: unknown glyph 'x'"},
+		{"unknown glyph after legend", "# = obstacle\n---\n#x#\n", "line 3 column 2"},
+		{"two players", "M M\n", "line 1 column 3: second player"},
+		{"bad legend", "## = obstacle\n---\n##\n", "line 1"},
+		{"bad legend type", "# = lava\n---\n##\n", "unknown object type"},
+		{"empty", "", "no rows"},
+		{"settings for terrain", "# = obstacle radius=2\n---\n##\n", "only torches have settings"},
+		{"bad torch setting", "* = torch brightness=2\n---\n*\n", "unknown torch setting"},
+		{"bad torch number", "* = torch radius=far\n---\n*\n", "radius: \"far\" is not a number"},
+		{"bad torch colour", "* = torch colour=1,2\n---\n*\n", "colour: needs red, green and blue"},
+		{"torch too bright", "* = torch intensity=20\n---\n*\n", "line 1: intensity: must be between 0, for the default, and 12"},
+		{"torch flicker", "* = torch flicker=100,-5\n---\n*\n", "flicker[1]: must be between 0 and 100"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			_, err := ReadASCIIMap(strings.NewReader(test.input))
+			assert.ErrorContains(t, err, test.err)
+		})
+	}
+}
+
+func TestASCIIMapNewWorld(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	am, err := ReadASCIIMap(strings.NewReader("" +
+		"@@@@@@@\n" +
+		"@M   E@\n" +
+		"@  ^  @\n" +
+		"@@@@@@@\n"))
+	require.NoError(t, err)
+
+	w := am.NewWorld(logger, NewConfig().WithNPCs().WithSeed(1))
+
+	assert.Equal(t, image.Point{X: 1, Y: 1}, *w.Player.Location, "Player should spawn on M")
+	assert.Len(t, w.Beings, 2, "The drawn NPC should be created")
+	assert.Len(t, *w.Lights, 1)
+	assert.True(t, w.Move(w.Player, object.East), "Player should be able to walk on the drawn floor")
+	assert.False(t, w.Move(w.Player, object.North), "Player should not walk through drawn walls")
+
+	// The world should have its own copy of the map
+	w.Geography.SetLoc(image.Point{X: 3, Y: 2}, nil)
+	assert.Equal(t, object.TorchType, am.Geography.At(image.Point{X: 3, Y: 2}).Top().Ident().Type, "Changing the world should not change the map")
+}
+
+func TestASCIIMapFromConfig(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	fc, err := LoadConfig("../../config/arena.json")
+	require.NoError(t, err)
+
+	w, err := fc.NewWorld(logger)
+	require.NoError(t, err)
+
+	assert.Equal(t, image.Point{X: 18, Y: 5}, *w.Player.Location, "Player should spawn where the map says")
+	assert.Len(t, *w.Lights, 4)
+	assert.Equal(t, 5, w.TorchRadius())
+}
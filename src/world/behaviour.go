@@ -0,0 +1,234 @@
+// Package provides the state machine that decides how NPCs move.
+package world
+
+import (
+	"gobotworld/src/geometry"
+	"gobotworld/src/world/object"
+	"image"
+)
+
+// State is what an NPC is doing.
+type State int
+
+const (
+	Wander State = iota
+	Patrol
+	Chase
+	Flee
+	ReturnHome
+)
+
+var stateNames = map[State]string{
+	Wander:     "wander",
+	Patrol:     "patrol",
+	Chase:      "chase",
+	Flee:       "flee",
+	ReturnHome: "return home",
+}
+
+func (s State) String() string {
+	if name, ok := stateNames[s]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+const (
+	DefaultSight = 8
+	DefaultLeash = 20
+)
+
+// Behaviour drives one NPC. NPCs wander, or patrol their route if they have
+// one, until the player comes within sight. Then they chase the player, or
+// flee from it if they are timid, and once it is out of sight they return
+// home and carry on as before.
+type Behaviour struct {
+	State State         `json:"state"`
+	Home  image.Point   `json:"home"`
+	Route []image.Point `json:"route,omitempty"`
+	// Waypoint is the index of the point of the route being walked to.
+	Waypoint int `json:"waypoint,omitempty"`
+	// Sight is how close the player has to be to be noticed. By night a
+	// player standing in light is noticed from twice as far.
+	Sight int `json:"sight"`
+	// Leash is how far from home the player can get before a chase is given up.
+	Leash int `json:"leash"`
+	// Timid NPCs flee from the player instead of chasing it.
+	Timid bool `json:"timid,omitempty"`
+}
+
+// NewBehaviour is the behaviour an NPC starts with. Home is where it starts
+// and critters are timid.
+func NewBehaviour(being *object.Character, route ...image.Point) *Behaviour {
+	b := &Behaviour{
+		State: Wander,
+		Home:  *being.Location,
+		Route: route,
+		Sight: DefaultSight,
+		Leash: DefaultLeash,
+		Timid: being.Ident().Type == object.CritterType,
+	}
+	b.State = b.idle()
+	return b
+}
+
+// idle is the state to be in when the player isn't about.
+func (b *Behaviour) idle() State {
+	if len(b.Route) > 0 {
+		return Patrol
+	}
+	return Wander
+}
+
+// Behaviour returns a copy of what drives an NPC, false for the player and
+// beings that aren't in the world.
+func (world World) Behaviour(being *object.Character) (Behaviour, bool) {
+	b, ok := world.behaviours[being]
+	if !ok {
+		return Behaviour{}, false
+	}
+	return *b, true
+}
+
+// SetBehaviour changes what drives an NPC.
+func (world World) SetBehaviour(being *object.Character, b Behaviour) {
+	world.behaviours[being] = &b
+}
+
+// think moves the state machine on given where the player is now.
+func (world World) think(being *object.Character, b *Behaviour) {
+	location := *being.Location
+	player := *world.Player.Location
+	sees := world.CanSee(location, player, world.sight(b, player))
+	inReach := geometry.Distance(b.Home, player) <= b.Leash
+	alarmed := Chase
+	if b.Timid {
+		alarmed = Flee
+	}
+
+	next := b.State
+	switch b.State {
+	case Wander, Patrol:
+		if sees && (b.Timid || inReach) {
+			next = alarmed
+		}
+	case Chase:
+		if !sees || !inReach {
+			next = ReturnHome
+		}
+	case Flee:
+		if !sees {
+			next = ReturnHome
+		}
+	case ReturnHome:
+		switch {
+		case sees && (b.Timid || inReach):
+			next = alarmed
+		case location == b.Home:
+			next = b.idle()
+		}
+	}
+
+	if next != b.State {
+		world.logger.Printf("NPC %d stops %s and starts %s", being.Ident().Index, b.State, next)
+		b.State = next
+	}
+}
+
+// sight is how far an NPC notices the player from, further by night when the
+// light map has the player lit up.
+func (world World) sight(b *Behaviour, player image.Point) int {
+	if cycle, _ := world.Cycle(); cycle == object.NightTime && world.lighting.Lumen(player) > 0 {
+		return 2 * b.Sight
+	}
+	return b.Sight
+}
+
+// act moves the NPC as its state says, returning whether it moved.
+func (world World) act(being *object.Character, b *Behaviour) bool {
+	location := *being.Location
+	switch b.State {
+	case Patrol:
+		if len(b.Route) == 0 {
+			return world.wander(being)
+		}
+		b.Waypoint %= len(b.Route)
+		if location == b.Route[b.Waypoint] {
+			b.Waypoint = (b.Waypoint + 1) % len(b.Route)
+		}
+		return world.stepTowards(being, b.Route[b.Waypoint])
+	case Chase:
+		return world.stepTowards(being, *world.Player.Location)
+	case Flee:
+		return world.fleeFrom(being, *world.Player.Location)
+	case ReturnHome:
+		if world.stepTowards(being, b.Home) {
+			return true
+		}
+		if location != b.Home {
+			// Home can't be reached, make wherever this is home instead
+			world.logger.Printf("NPC %d can't get home, staying at %v", being.Ident().Index, location)
+			b.Home = location
+		}
+		return false
+	default:
+		return world.wander(being)
+	}
+}
+
+// wander tries the directions in a random order and takes the first step that
+// can be made.
+func (world World) wander(being *object.Character) bool {
+	direction, ok := randomStep(world.rnd, func(direction object.Direction) bool {
+		return world.Move(being, direction)
+	})
+	if ok {
+		world.logger.Printf("NPC %d moved %s", being.Ident().Index, direction.String())
+	}
+	return ok
+}
+
+// planLimit is how far past the straight line distance a route may stray.
+const planLimit = 16
+
+// stepTowards takes the first step of the route to target. Next to the target
+// the NPC turns to face it instead.
+func (world World) stepTowards(being *object.Character, target image.Point) bool {
+	location := *being.Location
+	limit := int(manhattanDistance(location, target))*2 + planLimit
+	route := PathFinder{World: world}.Route(location, target, limit)
+	if len(route) < 2 {
+		return false
+	}
+	direction := directionOf(route[1].Sub(location))
+	if len(route) == 2 && !world.Geography.CanPass(target, being) {
+		being.Direction = direction
+		return false
+	}
+	return world.Move(being, direction)
+}
+
+// fleeFrom takes the step that gets furthest from p, if any step gets further.
+func (world World) fleeFrom(being *object.Character, p image.Point) bool {
+	location := *being.Location
+	best, bestDistance := object.Direction(0), geometry.Distance(location, p)
+	for _, direction := range directions {
+		next := location.Add(moveTransform[direction])
+		if d := geometry.Distance(next, p); d > bestDistance && world.Geography.CanPass(next, being) {
+			best, bestDistance = direction, d
+		}
+	}
+	if best == 0 {
+		return false
+	}
+	return world.Move(being, best)
+}
+
+func directionOf(delta image.Point) object.Direction {
+	for direction, d := range moveTransform {
+		if d == delta {
+			return direction
+		}
+	}
+	return 0
+}
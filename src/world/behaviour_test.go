@@ -0,0 +1,198 @@
+package world
+
+import (
+	"bytes"
+	"gobotworld/src/geometry"
+	"gobotworld/src/world/object"
+	"image"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// drawnWorld builds a world from a drawn map with only the NPCs drawn on it.
+func drawnWorld(t *testing.T, rows ...string) World {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	return drawnMap(t, rows...).NewWorld(logger, NewConfig().WithNPCs().WithSeed(1))
+}
+
+// drawnNPC returns the NPC of a world with one drawn on its map.
+func drawnNPC(t *testing.T, w World) *object.Character {
+	var npc *object.Character
+	for being, controlled := range w.Beings {
+		if !controlled {
+			npc = being
+		}
+	}
+	require.NotNil(t, npc)
+	return npc
+}
+
+func TestStateNames(t *testing.T) {
+	assert.Equal(t, "chase", Chase.String())
+	assert.Equal(t, "return home", ReturnHome.String())
+	assert.Equal(t, "unknown", State(42).String())
+}
+
+func TestEnemiesChaseThePlayer(t *testing.T) {
+	// Torches are in the way but can be seen past
+	w := drawnWorld(t,
+		"@@@@@@@@@@",
+		"@M  ^    @",
+		"@   ^  E @",
+		"@        @",
+		"@@@@@@@@@@",
+	)
+	enemy := drawnNPC(t, w)
+	b, _ := w.Behaviour(enemy)
+	assert.Equal(t, Wander, b.State, "Enemies wander to begin with")
+
+	for range 12 {
+		w.NpcMove()
+	}
+	b, _ = w.Behaviour(enemy)
+	assert.Equal(t, Chase, b.State, "The enemy should have seen the player")
+	assert.Equal(t, 1, geometry.Distance(*enemy.Location, *w.Player.Location), "The enemy should have found its way round the wall")
+	assert.Equal(t, w.Player.Location.Sub(*enemy.Location), moveTransform[enemy.Direction], "The enemy should face the player it caught")
+}
+
+func TestWallsHideThePlayer(t *testing.T) {
+	w := drawnWorld(t,
+		"@@@@@@@@@@",
+		"@M  @    @",
+		"@   @  E @",
+		"@   @    @",
+		"@        @",
+		"@@@@@@@@@@",
+	)
+	enemy := drawnNPC(t, w)
+	w.NpcMove()
+	b, _ := w.Behaviour(enemy)
+	assert.Equal(t, Wander, b.State, "The enemy can't see through the wall")
+}
+
+func TestChaseIsGivenUp(t *testing.T) {
+	w := drawnWorld(t,
+		"@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@",
+		"@M   E                            @",
+		"@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@",
+	)
+	enemy := drawnNPC(t, w)
+	w.NpcMove()
+	b, _ := w.Behaviour(enemy)
+	require.Equal(t, Chase, b.State)
+	home := b.Home
+
+	w.Relocate(w.Player, image.Point{X: 33, Y: 1})
+	w.NpcMove()
+	b, _ = w.Behaviour(enemy)
+	assert.Equal(t, ReturnHome, b.State, "The enemy should give up once the player is out of sight")
+	assert.Equal(t, home, *enemy.Location, "The enemy should head home")
+
+	w.NpcMove()
+	b, _ = w.Behaviour(enemy)
+	assert.Equal(t, Wander, b.State, "Back home the enemy goes back to wandering")
+}
+
+func TestCrittersFlee(t *testing.T) {
+	w := drawnWorld(t,
+		"@@@@@@@@@@@@",
+		"@M  c      @",
+		"@@@@@@@@@@@@",
+	)
+	critter := drawnNPC(t, w)
+	w.NpcMove()
+	b, _ := w.Behaviour(critter)
+	assert.Equal(t, Flee, b.State, "Critters should run from the player")
+	assert.Equal(t, image.Point{X: 5, Y: 1}, *critter.Location)
+
+	for range 10 {
+		w.NpcMove()
+	}
+	assert.GreaterOrEqual(t, geometry.Distance(*critter.Location, *w.Player.Location), DefaultSight, "Critters should keep out of sight")
+}
+
+func TestPatrolFollowsTheRoute(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	am := drawnMap(t,
+		"@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@",
+		"@M                                  @",
+		"@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@     @",
+		"@           @                       @",
+		"@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@@",
+	)
+	route := []image.Point{{X: 12, Y: 1}, {X: 30, Y: 3}}
+	w := am.NewWorld(logger, NewConfig().WithSeed(1).WithNPCs(NPCSpawn{Type: object.EnemyType, Location: &route[0], Route: route}))
+
+	var guard *object.Character
+	for being, controlled := range w.Beings {
+		if !controlled {
+			guard = being
+		}
+	}
+	b, _ := w.Behaviour(guard)
+	require.Equal(t, Patrol, b.State)
+
+	visited := map[image.Point]bool{}
+	for range 80 {
+		w.NpcMove()
+		visited[*guard.Location] = true
+	}
+	assert.True(t, visited[route[1]], "The guard should reach the far end of its route")
+	assert.True(t, visited[route[0]], "and come back again")
+}
+
+func TestRoute(t *testing.T) {
+	w := drawnWorld(t,
+		"@@@@@@@",
+		"@M @  @",
+		"@  @ E@",
+		"@     @",
+		"@@@@@@@",
+	)
+	enemy := drawnNPC(t, w)
+	pf := PathFinder{World: w}
+	route := pf.Route(*w.Player.Location, *enemy.Location, 0)
+	require.NotEmpty(t, route, "Routes may end on a being")
+	assert.Equal(t, *w.Player.Location, route[0])
+	assert.Equal(t, *enemy.Location, route[len(route)-1])
+	for i := 1; i < len(route); i++ {
+		assert.Equal(t, 1, geometry.Distance(route[i-1], route[i]), "Each step should be to a neighbour")
+	}
+
+	assert.Nil(t, pf.Route(*w.Player.Location, *enemy.Location, 3), "Routes should not stray past the limit")
+}
+
+func TestBehaviourIsSaved(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	w := drawnWorld(t,
+		"@@@@@@@@",
+		"@M   E @",
+		"@@@@@@@@",
+	)
+	enemy := drawnNPC(t, w)
+	w.NpcMove()
+
+	restored := saveAndLoad(t, w, logger)
+	var restoredEnemy *object.Character
+	for being := range restored.Beings {
+		if being.Ident() == enemy.Ident() {
+			restoredEnemy = being
+		}
+	}
+	original, _ := w.Behaviour(enemy)
+	loaded, ok := restored.Behaviour(restoredEnemy)
+	require.True(t, ok)
+	assert.Equal(t, original, loaded)
+}
+
+func saveAndLoad(t *testing.T, w World, logger *log.Logger) World {
+	var buf bytes.Buffer
+	require.NoError(t, w.Save(&buf))
+	restored, err := Load(logger, &buf)
+	require.NoError(t, err)
+	return restored
+}
@@ -0,0 +1,100 @@
+// Package provides the lights beings carry about with them, and putting them
+// down as torches and picking them up again.
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"slices"
+)
+
+// Carry gives a being a light to carry. It lights the cells around the being
+// wherever it goes from the next tick. A nil light takes away the one it has.
+func (world World) Carry(being *object.Character, light *object.Light) {
+	if light == nil {
+		delete(world.lanterns, being)
+		return
+	}
+	light.Location = being.Location
+	world.lanterns[being] = light
+}
+
+// Carried returns the light a being carries, false if it has none.
+func (world World) Carried(being *object.Character) (*object.Light, bool) {
+	light, ok := world.lanterns[being]
+	return light, ok
+}
+
+// carried returns the lights the beings carry in the order of the beings,
+// each moved to where its being is now.
+func (world World) carried() object.Lights {
+	if len(world.lanterns) == 0 {
+		return nil
+	}
+	var lights object.Lights
+	for _, being := range world.sortedBeings() {
+		if light, ok := world.lanterns[being]; ok {
+			light.Location = being.Location
+			lights = append(lights, light)
+		}
+	}
+	return lights
+}
+
+// Place puts the light a being carries down on the cell next to it in
+// direction, where it stays as a torch. It fails if the being has no light or
+// the cell can't be passed.
+func (world World) Place(being *object.Character, direction object.Direction) bool {
+	light, ok := world.lanterns[being]
+	if !ok {
+		return false
+	}
+	being.Direction = direction
+	p := being.Location.Add(moveTransform[direction])
+	if world.Geography.At(p) == nil || !world.Geography.CanPass(p, being) {
+		return false
+	}
+
+	delete(world.lanterns, being)
+	light.Location = &p
+	world.Geography.AddLoc(p, light)
+	*world.Lights = append(*world.Lights, light)
+	world.lookAgain()
+	return true
+}
+
+// Take picks up the light on the cell next to a being in direction and
+// carries it, leaving floor behind if there is nothing else there. It fails
+// if the being already carries a light or there is none to take.
+func (world World) Take(being *object.Character, direction object.Direction) bool {
+	if _, ok := world.lanterns[being]; ok {
+		return false
+	}
+	being.Direction = direction
+	p := being.Location.Add(moveTransform[direction])
+	light, ok := lightIn(world.Geography.At(p))
+	if !ok {
+		return false
+	}
+
+	world.Geography.RemoveLoc(p, light)
+	if len(world.Geography.At(p)) == 0 {
+		world.Geography.AddLoc(p, object.NewObject(0, object.Dirt1Type, true))
+	}
+	*world.Lights = slices.DeleteFunc(*world.Lights, func(l *object.Light) bool { return l == light })
+	world.Carry(being, light)
+	world.lookAgain()
+	return true
+}
+
+// lightIn finds the light among the things of a cell. Placed lights stand on
+// the floor, so it isn't always the first.
+func lightIn(things object.ThingList) (*object.Light, bool) {
+	i := slices.IndexFunc(things, func(thing object.Thing) bool {
+		_, ok := thing.(*object.Light)
+		return ok
+	})
+	if i < 0 {
+		return nil, false
+	}
+	return things[i].(*object.Light), true
+}
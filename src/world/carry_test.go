@@ -0,0 +1,170 @@
+package world
+
+import (
+	"bytes"
+	"gobotworld/src/world/object"
+	"image"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCarriedLightsMove(t *testing.T) {
+	w := drawnWorld(t,
+		"@@@@@@@@@@@@@@@@@@@@",
+		"@M                 @",
+		"@@@@@@@@@@@@@@@@@@@@",
+	)
+	lantern := object.NewLantern(3)
+	w.Carry(w.Player, lantern)
+	carried, ok := w.Carried(w.Player)
+	require.True(t, ok)
+	assert.Same(t, lantern, carried)
+	assert.Zero(t, w.LightMap().Lumen(*w.Player.Location), "Lanterns light the map from the next tick")
+
+	w.Tick()
+	assert.Equal(t, object.MaxLumen, w.LightMap().Lumen(image.Point{X: 1, Y: 1}))
+	for range 8 {
+		w.Enqueue(MoveAction(w.Player, object.East))
+		w.Tick()
+	}
+	assert.Equal(t, image.Point{X: 9, Y: 1}, *lantern.Location, "The lantern goes where the being does")
+	assert.Zero(t, w.LightMap().Lumen(image.Point{X: 1, Y: 1}), "The light goes with it")
+	assert.Equal(t, object.MaxLumen, w.LightMap().Lumen(image.Point{X: 9, Y: 1}))
+	assertLitLike(t, w, "Carried lights")
+
+	w.Carry(w.Player, nil)
+	w.Tick()
+	_, ok = w.Carried(w.Player)
+	assert.False(t, ok)
+	assert.Zero(t, w.LightMap().Lumen(image.Point{X: 9, Y: 1}), "Lanterns taken away light nothing")
+}
+
+func TestTakeAndPlace(t *testing.T) {
+	w := drawnWorld(t,
+		"@@@@@@@",
+		"@ M^  @",
+		"@@@@@@@",
+	)
+	torch := (*w.Lights)[0]
+	w.Tick()
+	assert.False(t, w.Place(w.Player, object.West), "There is nothing to place")
+	assert.False(t, w.Take(w.Player, object.West), "There is nothing to take")
+
+	w.Enqueue(TakeAction(w.Player, object.East))
+	w.Tick()
+	carried, ok := w.Carried(w.Player)
+	require.True(t, ok, "The torch should be carried")
+	assert.Same(t, torch, carried)
+	assert.Empty(t, *w.Lights, "Taken torches are no longer on the map")
+	assert.Equal(t, object.ThingList{object.NewObject(0, object.Dirt1Type, true)}, w.Geography.At(image.Point{X: 3, Y: 1}), "Floor is left behind")
+	assert.Equal(t, object.East, w.Player.Direction)
+	assert.Contains(t, w.LightSources(), torch, "Carried torches still give light")
+	assert.Equal(t, object.MaxLumen, w.LightMap().Lumen(*w.Player.Location))
+	assert.False(t, w.Take(w.Player, object.East), "Beings carry one light at a time")
+
+	assert.False(t, w.Place(w.Player, object.North), "Lights can't be placed in walls")
+	w.Enqueue(PlaceAction(w.Player, object.West))
+	w.Tick()
+	_, ok = w.Carried(w.Player)
+	assert.False(t, ok)
+	assert.Equal(t, object.Lights{torch}, *w.Lights, "Placed torches are on the map")
+	assert.Equal(t, image.Point{X: 1, Y: 1}, *torch.Location)
+	assert.Contains(t, w.Geography.At(image.Point{X: 1, Y: 1}), object.Thing(torch))
+	assert.False(t, w.Geography.CanPass(image.Point{X: 1, Y: 1}, w.Player), "Placed torches are in the way")
+	assertLitLike(t, w, "Placed torches")
+
+	remembered, _ := w.Memory(w.Player).Recall(image.Point{X: 1, Y: 1})
+	assert.Equal(t, object.TorchType, remembered, "Beings see the torch they placed without moving")
+}
+
+func TestTakeFromChunks(t *testing.T) {
+	cm, _ := newFlatChunks(8, 1)
+	w := InitChunkedWorld(log.New(io.Discard, "", 0), cm, NewConfig().WithSeed(1).WithNPCs().WithPlayerSpawn(image.Point{X: 1, Y: 0}))
+	torch := image.Point{}
+	require.True(t, w.Take(w.Player, object.West))
+	for _, light := range cm.Lights() {
+		assert.NotEqual(t, torch, *light.Location, "Torches taken from a chunk are gone from it")
+	}
+	w.Tick()
+	assertLitLike(t, w, "Torches taken from a chunk")
+}
+
+func TestLitPlayersAreNoticed(t *testing.T) {
+	w := drawnWorld(t,
+		"@@@@@@@@@@@@@@@",
+		"@M          E @",
+		"@@@@@@@@@@@@@@@",
+	)
+	enemy := drawnNPC(t, w)
+	*w.Time = w.dayLength * 3 / 4
+	cycle, _ := w.Cycle()
+	require.Equal(t, object.NightTime, cycle)
+
+	w.Tick()
+	w.NpcMove()
+	b, _ := w.Behaviour(enemy)
+	assert.Equal(t, Wander, b.State, "The player is too far off to be noticed in the dark")
+
+	w.Carry(w.Player, object.NewLantern(2))
+	w.Tick()
+	w.NpcMove()
+	b, _ = w.Behaviour(enemy)
+	assert.Equal(t, Chase, b.State, "A lit player is noticed from further off on the next tick")
+}
+
+func TestSaveCarriedLight(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	original := drawnWorld(t,
+		"@@@@@@",
+		"@M   @",
+		"@@@@@@",
+	)
+	lantern := object.NewLantern(3)
+	lantern.Flicker = []int{100, 90}
+	original.Carry(original.Player, lantern)
+	original.Tick()
+
+	var buf bytes.Buffer
+	require.NoError(t, original.Save(&buf))
+	restored, err := Load(logger, &buf)
+	require.NoError(t, err)
+
+	carried, ok := restored.Carried(restored.Player)
+	require.True(t, ok, "Lanterns should be restored")
+	assert.Equal(t, lantern.Flicker, carried.Flicker)
+	assert.Same(t, restored.Player.Location, carried.Location)
+	assert.Equal(t, original.Hash(), restored.Hash())
+}
+
+func TestSavePlacedLight(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	original := drawnWorld(t,
+		"@@@@@@",
+		"@M   @",
+		"@@@@@@",
+	)
+	lantern := object.NewLantern(2)
+	lantern.Flicker = []int{100, 50}
+	original.Carry(original.Player, lantern)
+	require.True(t, original.Place(original.Player, object.East))
+	original.Tick()
+
+	var buf bytes.Buffer
+	require.NoError(t, original.Save(&buf))
+	restored, err := Load(logger, &buf)
+	require.NoError(t, err)
+
+	p := image.Point{X: 2, Y: 1}
+	require.Len(t, *restored.Lights, 1)
+	placed := (*restored.Lights)[0]
+	assert.Equal(t, 2, placed.Radius, "Placed lights should keep their own radius")
+	assert.Equal(t, object.LanternColour, placed.Colour)
+	assert.Equal(t, lantern.Flicker, placed.Flicker)
+	assert.Equal(t, p, *placed.Location)
+	assert.Equal(t, object.ThingList{object.NewObject(0, object.Dirt1Type, true), placed}, restored.Geography.At(p), "The floor under placed lights should be kept")
+	assert.Equal(t, original.Hash(), restored.Hash())
+}
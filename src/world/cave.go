@@ -0,0 +1,91 @@
+// Package provides a cellular automata cave generator.
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"image"
+)
+
+// CaveGenerator scatters walls at random and then repeatedly smooths them, a
+// cell becoming wall when most of its neighbours are, which leaves open caverns
+// joined by winding passages. The edge of the map is always wall.
+type CaveGenerator struct {
+	// FillChance is the chance of a cell starting out as wall.
+	FillChance float64
+	// Steps is how many rounds of smoothing to run.
+	Steps       int
+	Floor       object.Thing
+	Wall        object.Thing
+	TorchChance float64
+}
+
+func DefaultCave() CaveGenerator {
+	return CaveGenerator{
+		FillChance:  0.45,
+		Steps:       5,
+		Floor:       object.NewObject(0, object.Dirt1Type, true),
+		Wall:        object.NewObject(0, object.ObstacleType, false),
+		TorchChance: 1.0 / 150,
+	}
+}
+
+func (cg CaveGenerator) Generate(height, width int, cfg Config) (Map, object.Lights) {
+	rnd := cfg.Rand()
+
+	walls := make([][]bool, height)
+	for y := range walls {
+		walls[y] = make([]bool, width)
+		for x := range walls[y] {
+			walls[y][x] = onEdge(x, y, height, width) || rnd.Float64() < cg.FillChance
+		}
+	}
+
+	for range cg.Steps {
+		next := make([][]bool, height)
+		for y := range next {
+			next[y] = make([]bool, width)
+			for x := range next[y] {
+				next[y][x] = onEdge(x, y, height, width) || wallsAround(walls, x, y) >= 5
+			}
+		}
+		walls = next
+	}
+
+	geography := make(Map, height)
+	var lights object.Lights
+	for y := range geography {
+		geography[y] = make([]object.ThingList, width)
+		for x := range geography[y] {
+			thing := cg.Floor
+			switch {
+			case walls[y][x]:
+				thing = cg.Wall
+			case rnd.Float64() < cg.TorchChance:
+				light := cfg.newTorch(image.Point{X: x, Y: y})
+				thing = light
+				lights = append(lights, light)
+			}
+			geography[y][x] = object.ThingList{thing}
+		}
+	}
+	return geography, lights
+}
+
+func onEdge(x, y, height, width int) bool {
+	return x == 0 || y == 0 || x == width-1 || y == height-1
+}
+
+// wallsAround counts the walls in the 3x3 block centred on (x, y), counting
+// cells off the map as walls.
+func wallsAround(walls [][]bool, x, y int) int {
+	count := 0
+	for dy := -1; dy <= 1; dy++ {
+		for dx := -1; dx <= 1; dx++ {
+			ny, nx := y+dy, x+dx
+			if ny < 0 || nx < 0 || ny >= len(walls) || nx >= len(walls[ny]) || walls[ny][nx] {
+				count++
+			}
+		}
+	}
+	return count
+}
@@ -0,0 +1,58 @@
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCaveEdgesAreWalls(t *testing.T) {
+	geography, _ := DefaultCave().Generate(30, 50, EmptyConfig().WithSeed(1))
+
+	for x := 0; x < 50; x++ {
+		assert.False(t, geography.CanPass(image.Point{X: x, Y: 0}, nil), "Top edge should be wall")
+		assert.False(t, geography.CanPass(image.Point{X: x, Y: 29}, nil), "Bottom edge should be wall")
+	}
+	for y := 0; y < 30; y++ {
+		assert.False(t, geography.CanPass(image.Point{X: 0, Y: y}, nil), "Left edge should be wall")
+		assert.False(t, geography.CanPass(image.Point{X: 49, Y: y}, nil), "Right edge should be wall")
+	}
+}
+
+func TestCaveHasOpenSpace(t *testing.T) {
+	geography, lights := DefaultCave().Generate(80, 80, EmptyConfig().WithSeed(2))
+
+	walls := 0
+	for _, row := range geography {
+		for _, things := range row {
+			if things.Top().Ident().Type == object.ObstacleType {
+				walls++
+			}
+		}
+	}
+	ratio := float64(walls) / (80 * 80)
+	assert.Greater(t, ratio, 0.2, "Cave should have walls")
+	assert.Less(t, ratio, 0.7, "Cave should have room to move")
+
+	for _, light := range lights {
+		assert.Equal(t, object.TorchType, geography.At(*light.Location).Top().Ident().Type, "Every light should be a torch")
+	}
+}
+
+func TestCaveIsDeterministic(t *testing.T) {
+	first, _ := DefaultCave().Generate(40, 40, EmptyConfig().WithSeed(3))
+	second, _ := DefaultCave().Generate(40, 40, EmptyConfig().WithSeed(3))
+	assert.Equal(t, first, second, "Same seed should produce the same cave")
+}
+
+func TestWallsAround(t *testing.T) {
+	walls := [][]bool{
+		{false, false, false},
+		{false, true, false},
+		{false, false, false},
+	}
+	assert.Equal(t, 1, wallsAround(walls, 1, 1), "Only the centre is wall")
+	assert.Equal(t, 6, wallsAround(walls, 0, 0), "Cells off the map count as walls")
+}
@@ -0,0 +1,249 @@
+// Package provides an unbounded map that is generated a chunk at a time.
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"image"
+	"iter"
+	"maps"
+	"slices"
+)
+
+const (
+	DefaultChunkSize   = 32
+	DefaultChunkRadius = 2
+)
+
+// ChunkGenerator builds one chunk of an unbounded map. It must give the same
+// terrain and lights every time it is asked for the same area. The returned
+// map is indexed from the top left of the area, the lights are in map
+// coordinates.
+type ChunkGenerator interface {
+	GenerateChunk(area image.Rectangle) (Map, object.Lights)
+}
+
+// unbounded is how far a ChunkedMap reaches, far enough that nobody walks to
+// the edge and small enough that nothing overflows.
+var unbounded = image.Rect(-1<<30, -1<<30, 1<<30, 1<<30)
+
+type chunk struct {
+	cells  Map
+	lights object.Lights
+	// edited is set once anything other than a character has changed, the
+	// chunk is then kept when it is unloaded instead of being generated again.
+	edited bool
+}
+
+// ChunkedMap is a Grid without edges. It is split into square chunks that are
+// generated when a being comes within Radius chunks of them and unloaded once
+// every being is further away than that. The cells of a chunk that isn't
+// loaded don't exist, At returns nil for them and nothing can pass them.
+type ChunkedMap struct {
+	size      int
+	radius    int
+	generator ChunkGenerator
+	loaded    map[image.Point]*chunk
+	kept      map[image.Point]*chunk
+}
+
+// NewChunkedMap creates an empty map whose chunks are size cells square and
+// come from generator. Nothing is loaded until the first call to Stream.
+func NewChunkedMap(generator ChunkGenerator, size, radius int) *ChunkedMap {
+	// A being always needs a loaded chunk next to it to step into
+	return &ChunkedMap{
+		size:      max(size, 1),
+		radius:    max(radius, 1),
+		generator: generator,
+		loaded:    map[image.Point]*chunk{},
+		kept:      map[image.Point]*chunk{},
+	}
+}
+
+// ChunkOf returns the coordinate of the chunk that p is in.
+func (cm *ChunkedMap) ChunkOf(p image.Point) image.Point {
+	return image.Point{X: floorDiv(p.X, cm.size), Y: floorDiv(p.Y, cm.size)}
+}
+
+func floorDiv(a, b int) int {
+	q := a / b
+	if a%b != 0 && (a < 0) != (b < 0) {
+		q--
+	}
+	return q
+}
+
+func (cm *ChunkedMap) area(key image.Point) image.Rectangle {
+	min := key.Mul(cm.size)
+	return image.Rectangle{Min: min, Max: min.Add(image.Point{X: cm.size, Y: cm.size})}
+}
+
+// Loaded returns the coordinates of the loaded chunks, row by row.
+func (cm *ChunkedMap) Loaded() []image.Point {
+	keys := slices.Collect(maps.Keys(cm.loaded))
+	slices.SortFunc(keys, func(a, b image.Point) int {
+		if a.Y != b.Y {
+			return a.Y - b.Y
+		}
+		return a.X - b.X
+	})
+	return keys
+}
+
+// load makes sure a chunk is loaded, returning whether it wasn't already.
+func (cm *ChunkedMap) load(key image.Point) (*chunk, bool) {
+	if c, ok := cm.loaded[key]; ok {
+		return c, false
+	}
+	c, ok := cm.kept[key]
+	if ok {
+		delete(cm.kept, key)
+	} else {
+		cells, lights := cm.generator.GenerateChunk(cm.area(key))
+		c = &chunk{cells: cells, lights: lights}
+	}
+	cm.loaded[key] = c
+	return c, true
+}
+
+func (cm *ChunkedMap) unload(key image.Point) {
+	if c := cm.loaded[key]; c.edited {
+		cm.kept[key] = c
+	}
+	delete(cm.loaded, key)
+}
+
+// Stream loads every chunk within the radius of a focus point and unloads
+// those more than a chunk further out than that, so a being walking along a
+// chunk border doesn't keep loading and unloading the chunks beside it. It
+// returns the areas of the chunks it loaded.
+func (cm *ChunkedMap) Stream(focus []image.Point) []image.Rectangle {
+	var loaded []image.Rectangle
+	centres := make([]image.Point, 0, len(focus))
+	for _, p := range focus {
+		centre := cm.ChunkOf(p)
+		centres = append(centres, centre)
+		for dy := -cm.radius; dy <= cm.radius; dy++ {
+			for dx := -cm.radius; dx <= cm.radius; dx++ {
+				key := centre.Add(image.Point{X: dx, Y: dy})
+				if _, ok := cm.load(key); ok {
+					loaded = append(loaded, cm.area(key))
+				}
+			}
+		}
+	}
+
+	for _, key := range cm.Loaded() {
+		near := false
+		for _, centre := range centres {
+			if max(abs(key.X-centre.X), abs(key.Y-centre.Y)) <= cm.radius+1 {
+				near = true
+				break
+			}
+		}
+		if !near {
+			cm.unload(key)
+		}
+	}
+	return loaded
+}
+
+// Lights returns the lights of the loaded chunks, leaving out those that have
+// been taken away.
+func (cm *ChunkedMap) Lights() object.Lights {
+	var lights object.Lights
+	for _, key := range cm.Loaded() {
+		for _, light := range cm.loaded[key].lights {
+			if slices.Contains(cm.At(*light.Location), object.Thing(light)) {
+				lights = append(lights, light)
+			}
+		}
+	}
+	return lights
+}
+
+func (cm *ChunkedMap) At(p image.Point) object.ThingList {
+	key := cm.ChunkOf(p)
+	c, ok := cm.loaded[key]
+	if !ok {
+		return nil
+	}
+	local := p.Sub(cm.area(key).Min)
+	return c.cells[local.Y][local.X]
+}
+
+// SetLoc replaces the things at p, generating its chunk first if need be.
+func (cm *ChunkedMap) SetLoc(p image.Point, things object.ThingList) {
+	cm.set(p, things, true)
+}
+
+func (cm *ChunkedMap) set(p image.Point, things object.ThingList, edit bool) {
+	key := cm.ChunkOf(p)
+	c, _ := cm.load(key)
+	local := p.Sub(cm.area(key).Min)
+	c.cells[local.Y][local.X] = things
+	c.edited = c.edited || edit
+}
+
+func (cm *ChunkedMap) AddLoc(p image.Point, thing object.Thing) {
+	_, moving := thing.(*object.Character)
+	cm.set(p, append(cm.At(p), thing), !moving)
+}
+
+func (cm *ChunkedMap) RemoveLoc(p image.Point, thing object.Thing) {
+	_, moving := thing.(*object.Character)
+	cm.set(p, cm.At(p).DeleteItem(thing), !moving)
+}
+
+func (cm *ChunkedMap) CanPass(p image.Point, thing object.Thing) bool {
+	things := cm.At(p)
+	if things == nil {
+		return false
+	}
+	for _, obj := range things {
+		if !obj.Passable(thing) {
+			return false
+		}
+	}
+	return true
+}
+
+func (cm *ChunkedMap) Bounds() image.Rectangle {
+	return unbounded
+}
+
+// Cells visits the loaded chunks row by row, and the cells of each chunk row
+// by row.
+func (cm *ChunkedMap) Cells() iter.Seq2[image.Point, object.ThingList] {
+	return func(yield func(image.Point, object.ThingList) bool) {
+		for _, key := range cm.Loaded() {
+			origin := cm.area(key).Min
+			for p, things := range cm.loaded[key].cells.Cells() {
+				if !yield(origin.Add(p), things) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// view copies the cells of area into a Map indexed from its top left. The
+// lists of things are shared, not copied.
+func (cm *ChunkedMap) view(area image.Rectangle) Map {
+	view := make(Map, area.Dy())
+	for y := range view {
+		view[y] = make([]object.ThingList, area.Dx())
+		for x := range view[y] {
+			view[y][x] = cm.At(area.Min.Add(image.Point{X: x, Y: y}))
+		}
+	}
+	return view
+}
+
+// loadedArea is the smallest rectangle holding every loaded chunk.
+func (cm *ChunkedMap) loadedArea() image.Rectangle {
+	var area image.Rectangle
+	for key := range cm.loaded {
+		area = area.Union(cm.area(key))
+	}
+	return area
+}
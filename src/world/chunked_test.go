@@ -0,0 +1,173 @@
+package world
+
+import (
+	"bytes"
+	"gobotworld/src/world/object"
+	"image"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// flatChunks is dirt everywhere with a torch at the top left of every chunk.
+type flatChunks struct {
+	generated *int
+}
+
+func (fc flatChunks) GenerateChunk(area image.Rectangle) (Map, object.Lights) {
+	*fc.generated++
+	cells := make(Map, area.Dy())
+	for y := range cells {
+		cells[y] = make([]object.ThingList, area.Dx())
+		for x := range cells[y] {
+			cells[y][x] = object.ThingList{object.NewObject(0, object.Dirt1Type, true)}
+		}
+	}
+	torch := object.NewLight(area.Min, object.TorchArea)
+	cells[0][0] = object.ThingList{torch}
+	return cells, object.Lights{torch}
+}
+
+func newFlatChunks(size, radius int) (*ChunkedMap, *int) {
+	generated := 0
+	return NewChunkedMap(flatChunks{&generated}, size, radius), &generated
+}
+
+func TestChunkOf(t *testing.T) {
+	cm, _ := newFlatChunks(8, 1)
+	assert.Equal(t, image.Point{X: 0, Y: 0}, cm.ChunkOf(image.Point{X: 7, Y: 0}))
+	assert.Equal(t, image.Point{X: 1, Y: 2}, cm.ChunkOf(image.Point{X: 8, Y: 16}))
+	assert.Equal(t, image.Point{X: -1, Y: -1}, cm.ChunkOf(image.Point{X: -1, Y: -8}), "Negative cells round down")
+	assert.Equal(t, image.Point{X: -2, Y: 0}, cm.ChunkOf(image.Point{X: -9, Y: 0}))
+}
+
+func TestChunkedMapStreams(t *testing.T) {
+	cm, generated := newFlatChunks(8, 1)
+	assert.Nil(t, cm.At(image.Point{}), "Nothing exists before the first stream")
+	assert.False(t, cm.CanPass(image.Point{}, nil))
+
+	cm.Stream([]image.Point{{X: 0, Y: 0}})
+	assert.Len(t, cm.Loaded(), 9, "The chunk and the ring around it should load")
+	assert.Equal(t, 9, *generated)
+	assert.NotNil(t, cm.At(image.Point{X: -8, Y: -8}))
+	assert.Nil(t, cm.At(image.Point{X: 16, Y: 0}), "Chunks beyond the radius aren't loaded")
+
+	// One chunk east keeps everything within the unload margin
+	cm.Stream([]image.Point{{X: 8, Y: 0}})
+	assert.Len(t, cm.Loaded(), 12)
+
+	cm.Stream([]image.Point{{X: 100, Y: 100}})
+	assert.Len(t, cm.Loaded(), 9, "Distant chunks should be unloaded")
+	assert.Nil(t, cm.At(image.Point{X: 0, Y: 0}))
+	assert.Len(t, cm.Lights(), 9, "Only loaded chunks give light")
+}
+
+func TestChunkedMapRegeneratesUneditedChunks(t *testing.T) {
+	cm, generated := newFlatChunks(8, 1)
+	cm.Stream([]image.Point{{X: 0, Y: 0}})
+	p := image.Point{X: 3, Y: 3}
+	npc := object.NewNPC(p)
+	cm.AddLoc(p, npc)
+	cm.RemoveLoc(p, npc)
+	cm.Stream([]image.Point{{X: 100, Y: 0}})
+	before := *generated
+
+	cm.Stream([]image.Point{{X: 0, Y: 0}})
+	assert.Greater(t, *generated, before, "Chunks only passed through should be generated again")
+	assert.Equal(t, object.Dirt1Type, cm.At(p).Top().Ident().Type)
+}
+
+func TestChunkedMapKeepsEditedChunks(t *testing.T) {
+	cm, _ := newFlatChunks(8, 1)
+	cm.Stream([]image.Point{{X: 0, Y: 0}})
+	p := image.Point{X: -3, Y: 2}
+	cm.SetLoc(p, object.ThingList{object.NewObject(0, object.ObstacleType, false)})
+
+	cm.Stream([]image.Point{{X: 100, Y: 0}})
+	assert.Nil(t, cm.At(p))
+	cm.Stream([]image.Point{{X: 0, Y: 0}})
+	require.NotNil(t, cm.At(p))
+	assert.Equal(t, object.ObstacleType, cm.At(p).Top().Ident().Type, "Edits should survive the chunk being unloaded")
+}
+
+func TestChunkedMapCells(t *testing.T) {
+	cm, _ := newFlatChunks(4, 1)
+	cm.Stream([]image.Point{{X: 0, Y: 0}})
+
+	count := 0
+	var first image.Point
+	for p, things := range cm.Cells() {
+		if count == 0 {
+			first = p
+		}
+		assert.Equal(t, cm.At(p), things)
+		count++
+	}
+	assert.Equal(t, 9*4*4, count, "Every loaded cell should be visited")
+	assert.Equal(t, image.Point{X: -4, Y: -4}, first, "Cells start at the top left chunk")
+}
+
+func TestNoiseChunksMatchAcrossLoads(t *testing.T) {
+	chunks := DefaultNoise().Chunks(5, *object.NewLight(image.Point{}, object.TorchArea))
+	area := image.Rect(-16, 32, 0, 48)
+	first, firstLights := chunks.GenerateChunk(area)
+	second, secondLights := DefaultNoise().Chunks(5, *object.NewLight(image.Point{}, object.TorchArea)).GenerateChunk(area)
+	assert.Equal(t, first, second, "The same chunk should always come out the same")
+	assert.Equal(t, firstLights, secondLights)
+
+	whole, _ := chunks.GenerateChunk(image.Rect(-16, 32, 16, 48))
+	half, _ := chunks.GenerateChunk(image.Rect(0, 32, 16, 48))
+	assert.Equal(t, whole[3][16:], half[3], "Chunks should join up without seams")
+}
+
+func TestChunkedWorldFollowsThePlayer(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	cm, _ := newFlatChunks(8, 1)
+	w := InitChunkedWorld(logger, cm, NewConfig().WithSeed(1).WithPlayerSpawn(image.Point{X: 3, Y: 3}).WithNPCs())
+
+	start := *w.Player.Location
+	assert.Contains(t, cm.At(start), object.Thing(w.Player), "Player should be on the map")
+
+	for range 40 {
+		w.Enqueue(MoveAction(w.Player, object.East))
+		w.Tick()
+	}
+	assert.Equal(t, start.X+40, w.Player.Location.X, "Player should walk without meeting an edge")
+	assert.Nil(t, cm.At(start.Sub(image.Point{X: 16})), "The chunks left behind should be unloaded")
+	assert.NotNil(t, cm.At(w.Player.Location.Add(image.Point{X: 8})), "The chunks ahead should be loaded")
+	assert.NotEmpty(t, w.LightSources(), "Lights come from the loaded chunks")
+
+	path := PathFinder{World: w}.Find(*w.Player.Location, w.Player.Location.Add(image.Point{X: -12, Y: 5}))
+	assert.NotEmpty(t, path, "Paths should cross chunk borders")
+}
+
+func TestChunkedWorldCannotBeSaved(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	cm, _ := newFlatChunks(8, 1)
+	w := InitChunkedWorld(logger, cm, NewConfig().WithSeed(1))
+	assert.Error(t, w.Save(&bytes.Buffer{}))
+}
+
+func TestChunkedConfig(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	seed := int64(4)
+	fc := FileConfig{Seed: &seed, Chunks: &ChunkConfig{Size: 16}}
+	require.NoError(t, fc.Validate(), "Width, height and terrain aren't needed")
+
+	first, err := fc.NewWorld(logger)
+	require.NoError(t, err)
+	second, err := fc.NewWorld(logger)
+	require.NoError(t, err)
+	assert.Equal(t, first.Hash(), second.Hash(), "Same seed should give the same unbounded world")
+
+	fc.Generator = &GeneratorConfig{Type: "cave"}
+	fc.Chunks.Radius = -1
+	var fieldErr FieldError
+	err = fc.Validate()
+	require.ErrorAs(t, err, &fieldErr)
+	assert.ErrorContains(t, err, "generator.type")
+	assert.ErrorContains(t, err, "chunks.radius")
+}
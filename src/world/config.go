@@ -1,9 +1,11 @@
-// Package provides a collection of configuration for creating a world. Eventually, this will be moved to a file or some medium for configing the world.
+// Package provides a collection of configuration for creating a world. FileConfig reads the same settings from a file.
 package world
 
 import (
 	"gobotworld/src/world/object"
+	"image"
 	"math/rand"
+	"time"
 )
 
 type DefaultTerrain struct {
@@ -11,12 +13,34 @@ type DefaultTerrain struct {
 	Units int
 }
 
+// NPCSpawn describes an NPC to create with the world. When Location is nil the
+// NPC is put on a random passable cell. NPCs with a Route patrol it.
+type NPCSpawn struct {
+	Type     object.ObjectType
+	Location *image.Point
+	Route    []image.Point
+}
+
 type Config struct {
 	terrainTypes   []DefaultTerrain
 	terrainSum     int
+	generator      Generator
+	playerSpawn    *image.Point
+	npcs           []NPCSpawn
+	spawning       []SpawnRule
+	connectLights  bool
+	compact        bool
+	dayLength      int
+	torch          *object.Light
+	lantern        int
+	seed           int64
+	src            *countingSource
+	rnd            *rand.Rand
 	rndObjextIndex func(int) int // Useful for deterministic testing
 }
 
+// NewConfig creates a config from weighted terrain types. The config is seeded
+// from the clock, use WithSeed to make generation reproducible.
 func NewConfig(t ...DefaultTerrain) Config {
 	sum := 0
 	internalTerrain := make([]DefaultTerrain, 0, len(t))
@@ -25,11 +49,115 @@ func NewConfig(t ...DefaultTerrain) Config {
 		internalTerrain = append(internalTerrain, DefaultTerrain{item.Type, sum})
 	}
 	cfg := Config{
-		terrainTypes:   internalTerrain,
-		terrainSum:     sum,
-		rndObjextIndex: randomObjectIndex,
+		terrainTypes: internalTerrain,
+		terrainSum:   sum,
+		npcs:         []NPCSpawn{{Type: object.EnemyType, Location: &image.Point{X: 10, Y: 10}}},
+		dayLength:    object.DefaultDayLength,
 	}
-	return cfg
+	return cfg.WithSeed(time.Now().UnixNano())
+}
+
+// WithSeed returns a copy of the config drawing from a fresh random source
+// seeded with seed. The source is shared by every copy of the returned config
+// and is consumed by generation, so build a new one for each world that needs
+// to be reproduced.
+func (c Config) WithSeed(seed int64) Config {
+	c.seed = seed
+	c.src = newCountingSource(seed, 0)
+	c.rnd = rand.New(c.src)
+	c.rndObjextIndex = c.rnd.Intn
+	return c
+}
+
+// WithGenerator sets how InitWorld builds the map, Weighted is used otherwise.
+func (c Config) WithGenerator(g Generator) Config {
+	c.generator = g
+	return c
+}
+
+// WithPlayerSpawn places the player at p instead of the centre of the map.
+func (c Config) WithPlayerSpawn(p image.Point) Config {
+	c.playerSpawn = &p
+	return c
+}
+
+// WithNPCs replaces the NPCs created with the world.
+func (c Config) WithNPCs(npcs ...NPCSpawn) Config {
+	c.npcs = npcs
+	return c
+}
+
+// WithSpawnRules makes the world spawn and despawn NPCs as it runs.
+func (c Config) WithSpawnRules(rules ...SpawnRule) Config {
+	c.spawning = rules
+	return c
+}
+
+// WithConnectedLights makes InitWorld carve corridors so that every light can
+// be reached from where the player starts.
+func (c Config) WithConnectedLights() Config {
+	c.connectLights = true
+	return c
+}
+
+// WithCompactTiles makes InitWorld store the map as a TileMap, which is
+// slower to change but a fraction of the size. Only TileGenerators, such as
+// Weighted, build it without holding the whole map as a Map on the way.
+func (c Config) WithCompactTiles() Config {
+	c.compact = true
+	return c
+}
+
+// WithDayLength sets how many ticks a full day and night lasts.
+func (c Config) WithDayLength(ticks int) Config {
+	c.dayLength = ticks
+	return c
+}
+
+// WithTorchRadius sets how far the light of a torch reaches.
+func (c Config) WithTorchRadius(radius int) Config {
+	torch := c.torchLight()
+	torch.Radius = radius
+	return c.WithTorch(torch)
+}
+
+// WithTorch sets how the torches of generated maps burn, each is a copy of
+// torch wherever it is put.
+func (c Config) WithTorch(torch object.Light) Config {
+	c.torch = &torch
+	return c
+}
+
+// torchLight is how the torches of generated maps burn, a plain torch
+// reaching object.TorchArea unless the config says otherwise.
+func (c Config) torchLight() object.Light {
+	if c.torch == nil {
+		return *object.NewLight(image.Point{}, object.TorchArea)
+	}
+	return *c.torch
+}
+
+// newTorch makes a torch at p the way the config's torches burn.
+func (c Config) newTorch(p image.Point) *object.Light {
+	torch := c.torchLight()
+	return torch.CopyAt(p)
+}
+
+// WithLantern has the player start out carrying a lantern whose light reaches
+// radius cells.
+func (c Config) WithLantern(radius int) Config {
+	c.lantern = radius
+	return c
+}
+
+// Seed returns the seed the config's random source was created with.
+func (c Config) Seed() int64 {
+	return c.seed
+}
+
+// Rand is the random source generators should draw from.
+func (c Config) Rand() *rand.Rand {
+	return c.rnd
 }
 
 func (c Config) getObjectType(n int) object.Thing {
@@ -48,7 +176,3 @@ func (c Config) RandomObject() object.Thing {
 	rnd := c.rndObjextIndex(c.terrainSum)
 	return c.getObjectType(rnd)
 }
-
-func randomObjectIndex(max int) int {
-	return rand.Intn(max)
-}
@@ -56,3 +56,16 @@ func TestRandomObjectPanicsOnInvalidConfig(t *testing.T) {
 	config := NewConfig()
 	_ = config.RandomObject()
 }
+
+func TestWithSeedIsReproducible(t *testing.T) {
+	obj1 := object.NewObject(1, object.Dirt1Type, true)
+	obj2 := object.NewObject(2, object.RockType, true)
+
+	first := NewConfig(DefaultTerrain{Type: obj1, Units: 3}, DefaultTerrain{Type: obj2, Units: 7}).WithSeed(7)
+	second := NewConfig(DefaultTerrain{Type: obj1, Units: 3}, DefaultTerrain{Type: obj2, Units: 7}).WithSeed(7)
+
+	assert.Equal(t, int64(7), first.Seed(), "Config should remember its seed")
+	for i := 0; i < 20; i++ {
+		assert.Equal(t, first.RandomObject().Ident(), second.RandomObject().Ident(), "Same seed should draw the same terrain")
+	}
+}
@@ -0,0 +1,540 @@
+// Package provides loading a world configuration from a JSON file.
+package world
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gobotworld/src/world/object"
+	"image"
+	"image/color"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// FileConfig is the declarative form of a world, as read from a config file.
+// Zero values for DayLength and TorchRadius mean use the default.
+type FileConfig struct {
+	Width     int              `json:"width"`
+	Height    int              `json:"height"`
+	Seed      *int64           `json:"seed,omitempty"`
+	Generator *GeneratorConfig `json:"generator,omitempty"`
+	Terrain   []TerrainConfig  `json:"terrain"`
+	Player    *SpawnPoint      `json:"player,omitempty"`
+	NPCs      []NPCConfig      `json:"npcs,omitempty"`
+	Spawning  []SpawnConfig    `json:"spawning,omitempty"`
+	// ConnectLights carves corridors so every torch can be reached.
+	ConnectLights bool `json:"connectLights,omitempty"`
+	DayLength     int  `json:"dayLength,omitempty"`
+	TorchRadius   int  `json:"torchRadius,omitempty"`
+	// Torch sets how every torch of a generated map burns, its radius
+	// overrides TorchRadius.
+	Torch *LightConfig `json:"torch,omitempty"`
+	// Lantern is how far the light of the lantern the player starts with
+	// reaches, zero for no lantern.
+	Lantern int `json:"lantern,omitempty"`
+	// Chunks makes the map unbounded, Width and Height are then ignored.
+	Chunks *ChunkConfig `json:"chunks,omitempty"`
+	// Compact stores the map as a TileMap, for very large maps.
+	Compact bool `json:"compact,omitempty"`
+}
+
+// ChunkConfig sets up an unbounded map generated from noise a chunk at a time.
+// Zero values mean DefaultChunkSize and DefaultChunkRadius.
+type ChunkConfig struct {
+	Size   int `json:"size,omitempty"`
+	Radius int `json:"radius,omitempty"`
+}
+
+// GeneratorConfig picks how the map is built. Type is "weighted", which picks
+// each cell from the terrain weights, "noise", "cave", "dungeon" or "ascii",
+// which reads the map drawn in the file at Path and takes its size from it. The
+// remaining fields tune the generator of that type, zero means use the value
+// from DefaultNoise, DefaultCave or DefaultDungeon.
+type GeneratorConfig struct {
+	Type        string  `json:"type"`
+	Path        string  `json:"path,omitempty"`
+	Scale       float64 `json:"scale,omitempty"`
+	Octaves     int     `json:"octaves,omitempty"`
+	TorchChance float64 `json:"torchChance,omitempty"`
+	FillChance  float64 `json:"fillChance,omitempty"`
+	Steps       int     `json:"steps,omitempty"`
+	Rooms       int     `json:"rooms,omitempty"`
+	MinRoom     int     `json:"minRoom,omitempty"`
+	MaxRoom     int     `json:"maxRoom,omitempty"`
+}
+
+func (gc GeneratorConfig) generator() Generator {
+	switch gc.Type {
+	case "noise":
+		ng := DefaultNoise()
+		if gc.Scale > 0 {
+			ng.Scale = gc.Scale
+		}
+		if gc.Octaves > 0 {
+			ng.Octaves = gc.Octaves
+		}
+		if gc.TorchChance > 0 {
+			ng.TorchChance = gc.TorchChance
+		}
+		return ng
+	case "cave":
+		cg := DefaultCave()
+		if gc.FillChance > 0 {
+			cg.FillChance = gc.FillChance
+		}
+		if gc.Steps > 0 {
+			cg.Steps = gc.Steps
+		}
+		if gc.TorchChance > 0 {
+			cg.TorchChance = gc.TorchChance
+		}
+		return cg
+	case "dungeon":
+		dg := DefaultDungeon()
+		if gc.Rooms > 0 {
+			dg.Rooms = gc.Rooms
+		}
+		if gc.MinRoom > 0 {
+			dg.MinRoom = gc.MinRoom
+		}
+		if gc.MaxRoom > 0 {
+			dg.MaxRoom = gc.MaxRoom
+		}
+		return dg
+	default:
+		return Weighted
+	}
+}
+
+// TerrainConfig is one weighted terrain type. Passable defaults to false for
+// obstacles and torches and true for everything else.
+type TerrainConfig struct {
+	Type     string `json:"type"`
+	Units    int    `json:"units"`
+	Passable *bool  `json:"passable,omitempty"`
+}
+
+// LightConfig sets how a torch burns. Zero values keep the torch as it would
+// otherwise be, a radius of TorchRadius, TorchColour, full intensity and no
+// flicker.
+type LightConfig struct {
+	Radius    int       `json:"radius,omitempty"`
+	Colour    *[3]uint8 `json:"colour,omitempty"`
+	Intensity int       `json:"intensity,omitempty"`
+	// Flicker is the percentage of Intensity the torch burns at each tick,
+	// going round the list.
+	Flicker []int `json:"flicker,omitempty"`
+}
+
+// validate reports each setting that is out of range to fail by its field.
+func (lc LightConfig) validate(fail func(field, format string, args ...any)) {
+	if lc.Radius < 0 {
+		fail("radius", "must not be negative, got %d", lc.Radius)
+	}
+	if lc.Intensity < 0 || lc.Intensity > object.MaxLumen {
+		fail("intensity", "must be between 0, for the default, and %d, got %d", object.MaxLumen, lc.Intensity)
+	}
+	for i, percent := range lc.Flicker {
+		if percent < 0 || percent > 100 {
+			fail(fmt.Sprintf("flicker[%d]", i), "must be between 0 and 100, got %d", percent)
+		}
+	}
+}
+
+// apply returns torch with the settings given changed.
+func (lc LightConfig) apply(torch object.Light) object.Light {
+	if lc.Radius > 0 {
+		torch.Radius = lc.Radius
+	}
+	if lc.Colour != nil {
+		torch.Colour = color.RGBA{R: lc.Colour[0], G: lc.Colour[1], B: lc.Colour[2], A: 0xFF}
+	}
+	if lc.Intensity > 0 {
+		torch.Intensity = lc.Intensity
+	}
+	if len(lc.Flicker) > 0 {
+		torch.Flicker = slices.Clone(lc.Flicker)
+	}
+	return torch
+}
+
+// NPCConfig creates Count NPCs of a type. The first len(Spawns) are placed on
+// the given points, the rest on random passable cells. If there is a Route
+// every one of them patrols it.
+type NPCConfig struct {
+	Type   string       `json:"type"`
+	Count  int          `json:"count"`
+	Spawns []SpawnPoint `json:"spawns,omitempty"`
+	Route  []SpawnPoint `json:"route,omitempty"`
+}
+
+// SpawnConfig keeps Day NPCs of a type about during the day and Night of them
+// at night, checking every Every ticks. NPCs appear and vanish at least
+// MinDistance cells from the player, DefaultSpawnDistance if it is zero.
+type SpawnConfig struct {
+	Type        string `json:"type"`
+	Day         int    `json:"day"`
+	Night       int    `json:"night"`
+	Every       int    `json:"every"`
+	MinDistance int    `json:"minDistance,omitempty"`
+}
+
+type SpawnPoint struct {
+	X int `json:"x"`
+	Y int `json:"y"`
+}
+
+func (sp SpawnPoint) Point() image.Point {
+	return image.Point{X: sp.X, Y: sp.Y}
+}
+
+// FieldError is a validation failure in a config file. Field is the path to the
+// offending value, e.g. "terrain[2].units".
+type FieldError struct {
+	Field string
+	Msg   string
+}
+
+func (e FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Msg)
+}
+
+// DefaultFileConfig is the config file equivalent of DefaultWorld.
+func DefaultFileConfig() FileConfig {
+	return FileConfig{
+		Width:  Width,
+		Height: Height,
+		Terrain: []TerrainConfig{
+			{Type: "dirt1", Units: 400},
+			{Type: "dirt2", Units: 250},
+			{Type: "rock", Units: 50},
+			{Type: "obstacle", Units: 5},
+			{Type: "torch", Units: 1},
+		},
+		NPCs:        []NPCConfig{{Type: "enemy", Count: 1, Spawns: []SpawnPoint{{X: 10, Y: 10}}}},
+		DayLength:   object.DefaultDayLength,
+		TorchRadius: object.TorchArea,
+	}
+}
+
+// ReadConfig decodes and validates a config. Unknown fields are rejected so
+// that typos don't go unnoticed.
+func ReadConfig(r io.Reader) (FileConfig, error) {
+	var fc FileConfig
+	decoder := json.NewDecoder(r)
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&fc); err != nil {
+		return FileConfig{}, fmt.Errorf("reading config: %w", err)
+	}
+	if err := fc.Validate(); err != nil {
+		return FileConfig{}, err
+	}
+	return fc, nil
+}
+
+func LoadConfig(path string) (FileConfig, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return FileConfig{}, err
+	}
+	defer file.Close()
+
+	fc, err := ReadConfig(file)
+	if err != nil {
+		return FileConfig{}, fmt.Errorf("%s: %w", path, err)
+	}
+	// Drawn maps are found next to the config that uses them
+	if fc.Generator != nil && fc.Generator.Path != "" && !filepath.IsAbs(fc.Generator.Path) {
+		fc.Generator.Path = filepath.Join(filepath.Dir(path), fc.Generator.Path)
+	}
+	return fc, nil
+}
+
+// Validate returns every problem with the config joined together, each one a
+// FieldError.
+func (fc FileConfig) Validate() error {
+	var errs []error
+	fail := func(field, format string, args ...any) {
+		errs = append(errs, FieldError{Field: field, Msg: fmt.Sprintf(format, args...)})
+	}
+	ascii := fc.Generator != nil && fc.Generator.Type == "ascii"
+	chunked := fc.Chunks != nil
+	inBounds := func(field string, sp SpawnPoint) {
+		// The size of a drawn map isn't known until it has been read
+		if ascii && fc.Width == 0 && fc.Height == 0 || chunked {
+			return
+		}
+		if sp.X < 0 || sp.Y < 0 || sp.X >= fc.Width || sp.Y >= fc.Height {
+			fail(field, "(%d, %d) is outside the %dx%d map", sp.X, sp.Y, fc.Width, fc.Height)
+		}
+	}
+
+	if fc.Width <= 0 && !ascii && !chunked {
+		fail("width", "must be positive, got %d", fc.Width)
+	}
+	if fc.Height <= 0 && !ascii && !chunked {
+		fail("height", "must be positive, got %d", fc.Height)
+	}
+	if chunked {
+		if fc.Chunks.Size < 0 {
+			fail("chunks.size", "must not be negative, got %d", fc.Chunks.Size)
+		}
+		if fc.Chunks.Radius < 0 {
+			fail("chunks.radius", "must not be negative, got %d", fc.Chunks.Radius)
+		}
+		if fc.Generator != nil && fc.Generator.Type != "noise" {
+			fail("generator.type", "an unbounded map can only be generated from noise, got %q", fc.Generator.Type)
+		}
+		if fc.ConnectLights {
+			fail("connectLights", "torches can't be connected on an unbounded map")
+		}
+		if fc.Compact {
+			fail("compact", "an unbounded map is always stored in chunks")
+		}
+	}
+
+	weighted := !chunked
+	if gc := fc.Generator; gc != nil {
+		switch gc.Type {
+		case "weighted":
+		case "noise", "cave", "dungeon":
+			weighted = false
+		case "ascii":
+			weighted = false
+			if gc.Path == "" {
+				fail("generator.path", "an ascii map needs the path of the file it is drawn in")
+			}
+		default:
+			fail("generator.type", "unknown generator %q", gc.Type)
+		}
+		if gc.Scale < 0 {
+			fail("generator.scale", "must not be negative, got %g", gc.Scale)
+		}
+		if gc.Octaves < 0 {
+			fail("generator.octaves", "must not be negative, got %d", gc.Octaves)
+		}
+		if gc.TorchChance < 0 || gc.TorchChance > 1 {
+			fail("generator.torchChance", "must be between 0 and 1, got %g", gc.TorchChance)
+		}
+		if gc.FillChance < 0 || gc.FillChance > 1 {
+			fail("generator.fillChance", "must be between 0 and 1, got %g", gc.FillChance)
+		}
+		if gc.Steps < 0 {
+			fail("generator.steps", "must not be negative, got %d", gc.Steps)
+		}
+		if gc.Rooms < 0 {
+			fail("generator.rooms", "must not be negative, got %d", gc.Rooms)
+		}
+		if gc.MinRoom < 0 {
+			fail("generator.minRoom", "must not be negative, got %d", gc.MinRoom)
+		}
+		if gc.MaxRoom < 0 {
+			fail("generator.maxRoom", "must not be negative, got %d", gc.MaxRoom)
+		}
+		if gc.Type == "dungeon" {
+			if dg, ok := gc.generator().(DungeonGenerator); ok && dg.MinRoom > dg.MaxRoom {
+				fail("generator.minRoom", "%d is larger than maxRoom %d", dg.MinRoom, dg.MaxRoom)
+			}
+		}
+	}
+
+	if weighted && len(fc.Terrain) == 0 {
+		fail("terrain", "at least one terrain type is needed")
+	}
+	total := 0
+	for i, terrain := range fc.Terrain {
+		objType, err := object.ParseObjectType(terrain.Type)
+		if err != nil {
+			fail(fmt.Sprintf("terrain[%d].type", i), "%v", err)
+		} else if objType == object.PlayerType || objType == object.EnemyType {
+			fail(fmt.Sprintf("terrain[%d].type", i), "%s is a character, not terrain", terrain.Type)
+		}
+		if terrain.Units < 0 {
+			fail(fmt.Sprintf("terrain[%d].units", i), "must not be negative, got %d", terrain.Units)
+		}
+		total += terrain.Units
+	}
+	if weighted && len(fc.Terrain) > 0 && total == 0 {
+		fail("terrain", "units add up to zero")
+	}
+
+	if fc.Player != nil {
+		inBounds("player", *fc.Player)
+	}
+
+	for i, npc := range fc.NPCs {
+		if objType, err := object.ParseObjectType(npc.Type); err != nil {
+			fail(fmt.Sprintf("npcs[%d].type", i), "%v", err)
+		} else if !objType.IsNPC() {
+			fail(fmt.Sprintf("npcs[%d].type", i), "%s is not an NPC type", npc.Type)
+		}
+		if npc.Count < 0 {
+			fail(fmt.Sprintf("npcs[%d].count", i), "must not be negative, got %d", npc.Count)
+		}
+		if len(npc.Spawns) > npc.Count {
+			fail(fmt.Sprintf("npcs[%d].spawns", i), "has %d points for %d NPCs", len(npc.Spawns), npc.Count)
+		}
+		for j, spawn := range npc.Spawns {
+			inBounds(fmt.Sprintf("npcs[%d].spawns[%d]", i, j), spawn)
+		}
+		for j, point := range npc.Route {
+			inBounds(fmt.Sprintf("npcs[%d].route[%d]", i, j), point)
+		}
+	}
+
+	for i, spawn := range fc.Spawning {
+		if objType, err := object.ParseObjectType(spawn.Type); err != nil {
+			fail(fmt.Sprintf("spawning[%d].type", i), "%v", err)
+		} else if !objType.IsNPC() {
+			fail(fmt.Sprintf("spawning[%d].type", i), "%s is not an NPC type", spawn.Type)
+		}
+		if spawn.Day < 0 {
+			fail(fmt.Sprintf("spawning[%d].day", i), "must not be negative, got %d", spawn.Day)
+		}
+		if spawn.Night < 0 {
+			fail(fmt.Sprintf("spawning[%d].night", i), "must not be negative, got %d", spawn.Night)
+		}
+		if spawn.Every <= 0 {
+			fail(fmt.Sprintf("spawning[%d].every", i), "must be positive, got %d", spawn.Every)
+		}
+		if spawn.MinDistance < 0 {
+			fail(fmt.Sprintf("spawning[%d].minDistance", i), "must not be negative, got %d", spawn.MinDistance)
+		}
+	}
+
+	if fc.DayLength < 0 {
+		fail("dayLength", "must not be negative, got %d", fc.DayLength)
+	}
+	if fc.TorchRadius < 0 {
+		fail("torchRadius", "must not be negative, got %d", fc.TorchRadius)
+	}
+	if fc.Lantern < 0 {
+		fail("lantern", "must not be negative, got %d", fc.Lantern)
+	}
+	if fc.Torch != nil {
+		fc.Torch.validate(func(field, format string, args ...any) {
+			fail("torch."+field, format, args...)
+		})
+	}
+
+	return errors.Join(errs...)
+}
+
+// Config builds the world config described by the file. It assumes the file
+// has been validated.
+func (fc FileConfig) Config() Config {
+	terrain := make([]DefaultTerrain, 0, len(fc.Terrain))
+	for _, t := range fc.Terrain {
+		objType, _ := object.ParseObjectType(t.Type)
+		passable := objType != object.ObstacleType && objType != object.TorchType
+		if t.Passable != nil {
+			passable = *t.Passable
+		}
+		terrain = append(terrain, DefaultTerrain{object.NewObject(0, objType, passable), t.Units})
+	}
+	cfg := NewConfig(terrain...)
+	if fc.Seed != nil {
+		cfg = cfg.WithSeed(*fc.Seed)
+	}
+
+	if fc.Generator != nil {
+		cfg = cfg.WithGenerator(fc.Generator.generator())
+	}
+
+	if fc.Player != nil {
+		cfg = cfg.WithPlayerSpawn(fc.Player.Point())
+	}
+
+	var npcs []NPCSpawn
+	for _, npc := range fc.NPCs {
+		objType, _ := object.ParseObjectType(npc.Type)
+		var route []image.Point
+		for _, point := range npc.Route {
+			route = append(route, point.Point())
+		}
+		for i := 0; i < npc.Count; i++ {
+			spawn := NPCSpawn{Type: objType, Route: route}
+			if i < len(npc.Spawns) {
+				p := npc.Spawns[i].Point()
+				spawn.Location = &p
+			}
+			npcs = append(npcs, spawn)
+		}
+	}
+	cfg = cfg.WithNPCs(npcs...)
+
+	var rules []SpawnRule
+	for _, spawn := range fc.Spawning {
+		objType, _ := object.ParseObjectType(spawn.Type)
+		rule := SpawnRule{Type: objType, Day: spawn.Day, Night: spawn.Night, Every: spawn.Every, MinDistance: spawn.MinDistance}
+		if rule.MinDistance == 0 {
+			rule.MinDistance = DefaultSpawnDistance
+		}
+		rules = append(rules, rule)
+	}
+	cfg = cfg.WithSpawnRules(rules...)
+
+	if fc.ConnectLights {
+		cfg = cfg.WithConnectedLights()
+	}
+	if fc.Compact {
+		cfg = cfg.WithCompactTiles()
+	}
+	if fc.DayLength > 0 {
+		cfg = cfg.WithDayLength(fc.DayLength)
+	}
+	if fc.TorchRadius > 0 {
+		cfg = cfg.WithTorchRadius(fc.TorchRadius)
+	}
+	if fc.Torch != nil {
+		cfg = cfg.WithTorch(fc.Torch.apply(cfg.torchLight()))
+	}
+	if fc.Lantern > 0 {
+		cfg = cfg.WithLantern(fc.Lantern)
+	}
+	return cfg
+}
+
+// NewWorld validates the config and creates the world it describes.
+func (fc FileConfig) NewWorld(logger *log.Logger) (World, error) {
+	if err := fc.Validate(); err != nil {
+		return World{}, err
+	}
+	if fc.Chunks != nil {
+		return fc.newChunkedWorld(logger), nil
+	}
+	if fc.Generator == nil || fc.Generator.Type != "ascii" {
+		return InitWorld(logger, fc.Height, fc.Width, fc.Config()), nil
+	}
+
+	am, err := LoadASCIIMap(fc.Generator.Path)
+	if err != nil {
+		return World{}, FieldError{Field: "generator.path", Msg: err.Error()}
+	}
+	// Now the size is known the spawn points can be checked
+	fc.Width, fc.Height = am.Width(), am.Height()
+	if err := fc.Validate(); err != nil {
+		return World{}, err
+	}
+	return am.NewWorld(logger, fc.Config()), nil
+}
+
+func (fc FileConfig) newChunkedWorld(logger *log.Logger) World {
+	ng := DefaultNoise()
+	if fc.Generator != nil {
+		ng = fc.Generator.generator().(NoiseGenerator)
+	}
+	size, radius := DefaultChunkSize, DefaultChunkRadius
+	if fc.Chunks.Size > 0 {
+		size = fc.Chunks.Size
+	}
+	if fc.Chunks.Radius > 0 {
+		radius = fc.Chunks.Radius
+	}
+	cfg := fc.Config()
+	return InitChunkedWorld(logger, NewChunkedMap(ng.Chunks(cfg.Seed(), cfg.torchLight()), size, radius), cfg)
+}
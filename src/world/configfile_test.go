@@ -0,0 +1,175 @@
+package world
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"gobotworld/src/world/object"
+	"image"
+	"image/color"
+	"io"
+	"log"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadConfig(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	fc, err := ReadConfig(strings.NewReader(`{
+		"width": 30,
+		"height": 20,
+		"seed": 5,
+		"terrain": [{"type": "dirt1", "units": 1}],
+		"player": {"x": 3, "y": 4},
+		"npcs": [{"type": "enemy", "count": 2, "spawns": [{"x": 7, "y": 8}]}],
+		"dayLength": 40,
+		"torchRadius": 6,
+		"lantern": 3
+	}`))
+	require.NoError(t, err)
+
+	w, err := fc.NewWorld(logger)
+	require.NoError(t, err)
+
+	assert.Equal(t, 20, w.Geography.Bounds().Dy(), "Height should come from the file")
+	assert.Equal(t, 30, w.Geography.Bounds().Dx(), "Width should come from the file")
+	assert.Equal(t, int64(5), w.Seed(), "Seed should come from the file")
+	assert.Equal(t, image.Point{X: 3, Y: 4}, *w.Player.Location, "Player should spawn where the file says")
+	assert.Len(t, w.Beings, 3, "Player and both NPCs should exist")
+	assert.Equal(t, 6, w.TorchRadius(), "Torch radius should come from the file")
+	lantern, ok := w.Carried(w.Player)
+	require.True(t, ok, "The player should start with a lantern")
+	assert.Equal(t, 3, lantern.Radius)
+
+	*w.Time = 23
+	cycle, _ := w.Cycle()
+	assert.Equal(t, object.NightTime, cycle, "A 40 tick day should be dark by tick 23")
+
+	spawned := false
+	for being, isPlayer := range w.Beings {
+		if !isPlayer && *being.Location == (image.Point{X: 7, Y: 8}) {
+			spawned = true
+		}
+	}
+	assert.True(t, spawned, "The first NPC should use the spawn point")
+}
+
+func TestDefaultFileConfigMatchesExample(t *testing.T) {
+	fc, err := LoadConfig("../../config/default.json")
+	require.NoError(t, err, "The example config should be valid")
+	assert.Equal(t, DefaultFileConfig(), fc, "The example should be the default config")
+	assert.NoError(t, DefaultFileConfig().Validate(), "The default config should be valid")
+}
+
+func TestConfigValidationPointsAtField(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(fc *FileConfig)
+		field  string
+	}{
+		{"width", func(fc *FileConfig) { fc.Width = 0 }, "width"},
+		{"height", func(fc *FileConfig) { fc.Height = -1 }, "height"},
+		{"no terrain", func(fc *FileConfig) { fc.Terrain = nil }, "terrain"},
+		{"terrain type", func(fc *FileConfig) { fc.Terrain[2].Type = "lava" }, "terrain[2].type"},
+		{"terrain character", func(fc *FileConfig) { fc.Terrain[1].Type = "enemy" }, "terrain[1].type"},
+		{"terrain units", func(fc *FileConfig) { fc.Terrain[0].Units = -4 }, "terrain[0].units"},
+		{"player", func(fc *FileConfig) { fc.Player = &SpawnPoint{X: 500, Y: 1} }, "player"},
+		{"npc type", func(fc *FileConfig) { fc.NPCs[0].Type = "rock" }, "npcs[0].type"},
+		{"npc count", func(fc *FileConfig) { fc.NPCs[0].Count = -1 }, "npcs[0].count"},
+		{"npc spawns", func(fc *FileConfig) { fc.NPCs[0].Count = 0 }, "npcs[0].spawns"},
+		{"npc spawn point", func(fc *FileConfig) { fc.NPCs[0].Spawns[0].Y = -1 }, "npcs[0].spawns[0]"},
+		{"spawning type", func(fc *FileConfig) { fc.Spawning = []SpawnConfig{{Type: "torch", Every: 5}} }, "spawning[0].type"},
+		{"spawning night", func(fc *FileConfig) { fc.Spawning = []SpawnConfig{{Type: "enemy", Night: -1, Every: 5}} }, "spawning[0].night"},
+		{"spawning every", func(fc *FileConfig) { fc.Spawning = []SpawnConfig{{Type: "critter"}} }, "spawning[0].every"},
+		{"generator type", func(fc *FileConfig) { fc.Generator = &GeneratorConfig{Type: "fractal"} }, "generator.type"},
+		{"generator scale", func(fc *FileConfig) { fc.Generator = &GeneratorConfig{Type: "noise", Scale: -2} }, "generator.scale"},
+		{"generator torches", func(fc *FileConfig) { fc.Generator = &GeneratorConfig{Type: "noise", TorchChance: 2} }, "generator.torchChance"},
+		{"generator fill", func(fc *FileConfig) { fc.Generator = &GeneratorConfig{Type: "cave", FillChance: 1.5} }, "generator.fillChance"},
+		{"generator rooms", func(fc *FileConfig) { fc.Generator = &GeneratorConfig{Type: "dungeon", MinRoom: 20} }, "generator.minRoom"},
+		{"ascii path", func(fc *FileConfig) { fc.Generator = &GeneratorConfig{Type: "ascii"} }, "generator.path"},
+		{"day length", func(fc *FileConfig) { fc.DayLength = -1 }, "dayLength"},
+		{"torch radius", func(fc *FileConfig) { fc.TorchRadius = -1 }, "torchRadius"},
+		{"lantern", func(fc *FileConfig) { fc.Lantern = -1 }, "lantern"},
+		{"torch radius setting", func(fc *FileConfig) { fc.Torch = &LightConfig{Radius: -2} }, "torch.radius"},
+		{"torch intensity", func(fc *FileConfig) { fc.Torch = &LightConfig{Intensity: object.MaxLumen + 1} }, "torch.intensity"},
+		{"torch flicker", func(fc *FileConfig) { fc.Torch = &LightConfig{Flicker: []int{100, 120}} }, "torch.flicker[1]"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			fc := DefaultFileConfig()
+			test.modify(&fc)
+
+			data, _ := json.Marshal(fc)
+			_, err := ReadConfig(bytes.NewReader(data))
+
+			var fieldErr FieldError
+			require.True(t, errors.As(err, &fieldErr), "Validation should fail with a FieldError, got %v", err)
+			assert.Equal(t, test.field, fieldErr.Field, "Error should point at the offending field")
+		})
+	}
+}
+
+func TestConfigTorch(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	fc, err := ReadConfig(strings.NewReader(`{
+		"width": 30,
+		"height": 20,
+		"seed": 3,
+		"terrain": [{"type": "dirt1", "units": 5}, {"type": "torch", "units": 1}],
+		"torch": {"radius": 2, "colour": [100, 150, 255], "intensity": 8, "flicker": [100, 50]}
+	}`))
+	require.NoError(t, err)
+
+	w, err := fc.NewWorld(logger)
+	require.NoError(t, err)
+	require.NotEmpty(t, *w.Lights)
+	for _, light := range *w.Lights {
+		assert.Equal(t, 2, light.Radius, "Torches should reach as far as the file says")
+		assert.Equal(t, color.RGBA{R: 100, G: 150, B: 255, A: 0xFF}, light.Colour)
+		assert.Equal(t, 8, light.Intensity)
+		assert.Equal(t, []int{100, 50}, light.Flicker)
+	}
+	assert.NotSame(t, &(*w.Lights)[0].Flicker[0], &(*w.Lights)[1].Flicker[0], "Each torch flickers on its own")
+}
+
+func TestReadConfigRejectsUnknownFields(t *testing.T) {
+	_, err := ReadConfig(strings.NewReader(`{"width": 10, "height": 10, "terrian": []}`))
+	assert.ErrorContains(t, err, "terrian", "Typos should be reported")
+}
+
+func TestConfigNoiseGenerator(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	fc, err := ReadConfig(strings.NewReader(`{
+		"width": 50,
+		"height": 40,
+		"seed": 2,
+		"generator": {"type": "noise", "scale": 10}
+	}`))
+	require.NoError(t, err, "Noise worlds should not need terrain weights")
+
+	w, err := fc.NewWorld(logger)
+	require.NoError(t, err)
+
+	ng := DefaultNoise()
+	ng.Scale = 10
+	expected, _ := ng.Generate(40, 50, NewConfig().WithSeed(2))
+	assert.Equal(t, expected.At(image.Point{X: 5, Y: 5}), w.Geography.At(image.Point{X: 5, Y: 5}), "World should be built by the noise generator")
+}
+
+func TestExampleConfigsAreValid(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	paths, _ := filepath.Glob("../../config/*.json")
+	require.NotEmpty(t, paths)
+
+	for _, path := range paths {
+		fc, err := LoadConfig(path)
+		require.NoError(t, err, "%s should be valid", path)
+		_, err = fc.NewWorld(logger)
+		assert.NoError(t, err, "%s should build a world", path)
+	}
+}
@@ -0,0 +1,251 @@
+// Package provides connectivity analysis of maps and placing spawns where they can move.
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"image"
+	"math/rand"
+	"slices"
+)
+
+var compass = []image.Point{
+	{0, -1}, // North
+	{1, 0},  // East
+	{0, 1},  // South
+	{-1, 0}, // West
+}
+
+// Regions labels every cell of a map with the connected region of passable
+// cells it belongs to. Characters are ignored, they move and don't split the map.
+type Regions struct {
+	labels [][]int
+	// Sizes holds the number of cells in each region, indexed by label.
+	Sizes []int
+}
+
+// terrainPassable is Grid.CanPass without the characters.
+func terrainPassable(m Grid, p image.Point) bool {
+	things := m.At(p)
+	if things == nil {
+		return false
+	}
+	for _, thing := range things {
+		if _, ok := thing.(*object.Character); ok {
+			continue
+		}
+		if !thing.Passable(nil) {
+			return false
+		}
+	}
+	return true
+}
+
+// FindRegions flood fills the map, giving each set of passable cells that can
+// reach each other a label. The map must start at (0, 0).
+func FindRegions(m Grid) Regions {
+	bounds := m.Bounds()
+	regions := Regions{labels: make([][]int, bounds.Dy())}
+	for y := range regions.labels {
+		regions.labels[y] = make([]int, bounds.Dx())
+		for x := range regions.labels[y] {
+			regions.labels[y][x] = -1
+		}
+	}
+
+	for y := range regions.labels {
+		for x := range regions.labels[y] {
+			start := image.Point{X: x, Y: y}
+			if regions.labels[y][x] != -1 || !terrainPassable(m, start) {
+				continue
+			}
+
+			label := len(regions.Sizes)
+			size := 0
+			regions.labels[y][x] = label
+			queue := []image.Point{start}
+			for len(queue) > 0 {
+				p := queue[0]
+				queue = queue[1:]
+				size++
+				for _, off := range compass {
+					q := p.Add(off)
+					if regions.At(q) == -1 && terrainPassable(m, q) {
+						regions.labels[q.Y][q.X] = label
+						queue = append(queue, q)
+					}
+				}
+			}
+			regions.Sizes = append(regions.Sizes, size)
+		}
+	}
+	return regions
+}
+
+// At returns the label of the region p is in, or -1 if p is blocked or off the map.
+func (r Regions) At(p image.Point) int {
+	if p.Y < 0 || p.X < 0 || p.Y >= len(r.labels) || p.X >= len(r.labels[p.Y]) {
+		return -1
+	}
+	return r.labels[p.Y][p.X]
+}
+
+// Largest returns the label of the biggest region, or -1 if nothing is passable.
+func (r Regions) Largest() int {
+	largest := -1
+	for label, size := range r.Sizes {
+		if largest == -1 || size > r.Sizes[largest] {
+			largest = label
+		}
+	}
+	return largest
+}
+
+// Touches reports whether p is in the region or next to it, which is how
+// something impassable like a torch is reached.
+func (r Regions) Touches(p image.Point, label int) bool {
+	if r.At(p) == label {
+		return true
+	}
+	for _, off := range compass {
+		if r.At(p.Add(off)) == label {
+			return true
+		}
+	}
+	return false
+}
+
+// Nearest returns the cell of the region closest to p that isn't taken, and
+// false if there is none.
+func (r Regions) Nearest(label int, p image.Point, taken map[image.Point]bool) (image.Point, bool) {
+	best, found := image.Point{}, false
+	bestDist := 0
+	for y, row := range r.labels {
+		for x, l := range row {
+			q := image.Point{X: x, Y: y}
+			if l != label || taken[q] {
+				continue
+			}
+			d := (q.X-p.X)*(q.X-p.X) + (q.Y-p.Y)*(q.Y-p.Y)
+			if !found || d < bestDist {
+				best, bestDist, found = q, d, true
+			}
+		}
+	}
+	return best, found
+}
+
+// Cells returns every point in the region, row by row.
+func (r Regions) Cells(label int) []image.Point {
+	var cells []image.Point
+	for y, row := range r.labels {
+		for x, l := range row {
+			if l == label {
+				cells = append(cells, image.Point{X: x, Y: y})
+			}
+		}
+	}
+	return cells
+}
+
+// ConnectLights carves corridors of floor through whatever is in the way so
+// that every light can be reached from the largest region. Other torches are
+// never carved through. It returns the regions of the repaired map.
+func ConnectLights(m Grid, lights object.Lights, floor object.Thing) Regions {
+	regions := FindRegions(m)
+	for _, light := range lights {
+		largest := regions.Largest()
+		if largest == -1 || regions.Touches(*light.Location, largest) {
+			continue
+		}
+		path := corridorTo(m, regions, *light.Location, largest)
+		for _, p := range path {
+			if !terrainPassable(m, p) {
+				m.SetLoc(p, object.ThingList{floor})
+			}
+		}
+		regions = FindRegions(m)
+	}
+	return regions
+}
+
+// corridorTo finds the shortest run of cells from next to start into the
+// region, walking through anything except torches and the edge of the map.
+// Torches placed on the floor count as well as those filling a cell.
+func corridorTo(m Grid, regions Regions, start image.Point, label int) []image.Point {
+	from := map[image.Point]image.Point{start: start}
+	queue := []image.Point{start}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, off := range compass {
+			q := p.Add(off)
+			if _, seen := from[q]; seen || m.At(q) == nil || slices.ContainsFunc(m.At(q), isTorch) {
+				continue
+			}
+			from[q] = p
+			if regions.At(q) == label {
+				var path []image.Point
+				for c := p; c != start; c = from[c] {
+					path = append(path, c)
+				}
+				return path
+			}
+			queue = append(queue, q)
+		}
+	}
+	return nil
+}
+
+func isTorch(thing object.Thing) bool {
+	return thing.Ident().Type == object.TorchType
+}
+
+// spawner hands out cells in the largest region of a map, never the same one twice.
+type spawner struct {
+	regions Regions
+	largest int
+	rnd     *rand.Rand
+	taken   map[image.Point]bool
+	free    []image.Point
+}
+
+func newSpawner(regions Regions, rnd *rand.Rand) *spawner {
+	return &spawner{regions: regions, largest: regions.Largest(), rnd: rnd, taken: map[image.Point]bool{}}
+}
+
+// near returns p if it is a free cell of the largest region, otherwise the
+// closest one that is. If the map has nowhere to stand p is returned as is.
+func (s *spawner) near(p image.Point) image.Point {
+	if s.regions.At(p) != s.largest || s.taken[p] {
+		if q, ok := s.regions.Nearest(s.largest, p, s.taken); ok {
+			p = q
+		}
+	}
+	s.take(p)
+	return p
+}
+
+// random returns a free cell of the largest region picked at random.
+func (s *spawner) random() image.Point {
+	if s.largest == -1 {
+		return image.Point{}
+	}
+	if s.free == nil {
+		s.free = s.regions.Cells(s.largest)
+	}
+	for len(s.free) > 0 {
+		i := s.rnd.Intn(len(s.free))
+		p := s.free[i]
+		s.free[i] = s.free[len(s.free)-1]
+		s.free = s.free[:len(s.free)-1]
+		if !s.taken[p] {
+			s.take(p)
+			return p
+		}
+	}
+	return image.Point{}
+}
+
+func (s *spawner) take(p image.Point) {
+	s.taken[p] = true
+}
@@ -0,0 +1,142 @@
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"image"
+	"io"
+	"log"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func drawnMap(t *testing.T, rows ...string) ASCIIMap {
+	am, err := ReadASCIIMap(strings.NewReader(strings.Join(rows, "\n")))
+	require.NoError(t, err)
+	return am
+}
+
+func TestFindRegions(t *testing.T) {
+	am := drawnMap(t,
+		"@@@@@@@@@@",
+		"@    @  @@",
+		"@    @  @@",
+		"@@@@@@@@@@",
+	)
+	regions := FindRegions(am.Geography)
+
+	assert.Len(t, regions.Sizes, 2, "Map should split into two regions")
+	largest := regions.Largest()
+	assert.Equal(t, 8, regions.Sizes[largest], "Largest region is the room on the left")
+	assert.Equal(t, largest, regions.At(image.Point{X: 1, Y: 1}))
+	assert.NotEqual(t, largest, regions.At(image.Point{X: 6, Y: 1}), "Right pocket should be its own region")
+	assert.Equal(t, -1, regions.At(image.Point{X: 0, Y: 0}), "Walls are in no region")
+	assert.Equal(t, -1, regions.At(image.Point{X: -1, Y: 0}), "Off the map is in no region")
+	assert.Len(t, regions.Cells(largest), 8)
+}
+
+func TestRegionsIgnoreCharacters(t *testing.T) {
+	am := drawnMap(t,
+		"@@@@@",
+		"@   @",
+		"@@@@@",
+	)
+	am.Geography.AddLoc(image.Point{X: 2, Y: 1}, object.NewNPC(image.Point{X: 2, Y: 1}))
+
+	regions := FindRegions(am.Geography)
+	assert.Len(t, regions.Sizes, 1, "A character standing in a corridor should not split it")
+}
+
+func TestRegionsTouchesAndNearest(t *testing.T) {
+	am := drawnMap(t,
+		"@@@@@@",
+		"@  ^ @",
+		"@@@@@@",
+	)
+	regions := FindRegions(am.Geography)
+	largest := regions.Largest()
+
+	assert.True(t, regions.Touches(image.Point{X: 3, Y: 1}, largest), "Torch beside the floor can be reached")
+	assert.False(t, regions.Touches(image.Point{X: 0, Y: 0}, largest), "The corner is not beside the floor")
+
+	p, ok := regions.Nearest(largest, image.Point{X: 0, Y: 1}, nil)
+	assert.True(t, ok)
+	assert.Equal(t, image.Point{X: 1, Y: 1}, p, "Nearest free cell to the wall is beside it")
+
+	p, ok = regions.Nearest(largest, image.Point{X: 0, Y: 1}, map[image.Point]bool{{X: 1, Y: 1}: true})
+	assert.True(t, ok)
+	assert.Equal(t, image.Point{X: 2, Y: 1}, p, "Taken cells should be skipped")
+}
+
+func TestConnectLights(t *testing.T) {
+	am := drawnMap(t,
+		"@@@@@@@@@@",
+		"@     @@^@",
+		"@     @@@@",
+		"@@@@@@@@@@",
+	)
+	geography, lights := am.Generate(0, 0, Config{})
+
+	before := FindRegions(geography)
+	assert.False(t, before.Touches(*lights[0].Location, before.Largest()), "Torch starts walled off")
+
+	regions := ConnectLights(geography, lights, object.NewObject(0, object.Dirt1Type, true))
+	assert.True(t, regions.Touches(*lights[0].Location, regions.Largest()), "Torch should be reachable after carving")
+	assert.Equal(t, object.TorchType, geography.At(*lights[0].Location).Top().Ident().Type, "The torch itself should not be carved")
+}
+
+func TestConnectLightsAroundTorches(t *testing.T) {
+	am := drawnMap(t,
+		"@@@@@@@@@@",
+		"@   ^@@@^@",
+		"@   @@@@@@",
+		"@@@@@@@@@@",
+	)
+	geography, lights := am.Generate(0, 0, Config{})
+	blocking := image.Point{X: 4, Y: 1}
+	geography.SetLoc(blocking, object.ThingList{object.NewObject(0, object.Dirt1Type, true), object.NewLight(blocking, 2)})
+
+	regions := ConnectLights(geography, lights, object.NewObject(0, object.Dirt1Type, true))
+	assert.True(t, regions.Touches(*lights[1].Location, regions.Largest()), "Torch should be reachable after carving")
+	assert.Len(t, geography.At(blocking), 2, "Corridors go round torches standing on the floor")
+}
+
+func TestInitWorldSpawnsInLargestRegion(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	am := drawnMap(t,
+		"@@@@@@@@@@@@",
+		"@  @@@@    @",
+		"@  @@@@    @",
+		"@@@@@@@@@@@@",
+	)
+	cfg := NewConfig().WithSeed(1).
+		WithPlayerSpawn(image.Point{X: 1, Y: 1}).
+		WithNPCs(NPCSpawn{Type: object.EnemyType, Location: &image.Point{X: 4, Y: 1}}, NPCSpawn{Type: object.EnemyType})
+	w := InitWorld(logger, 4, 12, cfg.WithGenerator(am))
+
+	regions := FindRegions(w.Geography.(Map))
+	largest := regions.Largest()
+	taken := map[image.Point]bool{}
+	for being := range w.Beings {
+		assert.Equal(t, largest, regions.At(*being.Location), "Being %d should be in the largest region", being.Ident().Index)
+		assert.False(t, taken[*being.Location], "Beings should not share a cell")
+		taken[*being.Location] = true
+	}
+	assert.Equal(t, image.Point{X: 7, Y: 1}, *w.Player.Location, "Player should move to the nearest cell of the largest region")
+}
+
+func TestInitWorldConnectsLights(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	cfg := DefaultCave()
+	cfg.TorchChance = 0.02
+	w := InitWorld(logger, 60, 60, NewConfig().WithSeed(4).WithGenerator(cfg).WithConnectedLights())
+
+	regions := FindRegions(w.Geography.(Map))
+	playerRegion := regions.At(*w.Player.Location)
+	require.NotEmpty(t, *w.Lights)
+	for _, light := range *w.Lights {
+		assert.True(t, regions.Touches(*light.Location, playerRegion), "Torch at %v should be reachable from the player", *light.Location)
+	}
+}
@@ -0,0 +1,182 @@
+// Package provides controllers, which decide what a being does from what it can observe.
+package world
+
+import (
+	"gobotworld/src/geometry"
+	"gobotworld/src/world/object"
+	"image"
+	"math/rand"
+	"slices"
+)
+
+// ObservationRadius is how far around itself a being can observe.
+const ObservationRadius = 5
+
+// Cell is what a being can tell about one cell of the map.
+type Cell struct {
+	Point    image.Point         `json:"point"`
+	Things   []object.ObjectType `json:"things"`
+	Passable bool                `json:"passable"`
+	// Visible is false for cells the being can't see, which tell nothing.
+	Visible bool `json:"visible"`
+}
+
+// Observation is a copy of what a being can tell about the world around it.
+// Changing it changes nothing in the world.
+type Observation struct {
+	Tick     int               `json:"tick"`
+	Cycle    object.DayCycle   `json:"cycle"`
+	Index    int               `json:"index"`
+	Type     object.ObjectType `json:"type"`
+	Location image.Point       `json:"location"`
+	Facing   object.Direction  `json:"facing"`
+	// Light is how lit the cell the being stands on is.
+	Light int `json:"light"`
+	// Carrying is whether the being carries a light it can place.
+	Carrying bool `json:"carrying"`
+	Radius   int  `json:"radius"`
+	// Cells is the square of Radius around Location, row by row. Cells off
+	// the map or out of sight have no things and can't be passed.
+	Cells []Cell `json:"cells"`
+}
+
+// Cell returns the cell at p, false if p is further away than Radius.
+func (o Observation) Cell(p image.Point) (Cell, bool) {
+	d := p.Sub(o.Location)
+	if d.X < -o.Radius || d.X > o.Radius || d.Y < -o.Radius || d.Y > o.Radius {
+		return Cell{}, false
+	}
+	side := o.Radius*2 + 1
+	return o.Cells[(d.Y+o.Radius)*side+d.X+o.Radius], true
+}
+
+// Controller decides what a being does. Act is called once a tick, from the
+// goroutine that ticks the world, with what the being can observe. The Being
+// of the action returned is ignored, it is always the being controlled.
+type Controller interface {
+	Act(obs Observation) Action
+}
+
+// ControllerFunc lets a plain function be used as a Controller.
+type ControllerFunc func(obs Observation) Action
+
+func (f ControllerFunc) Act(obs Observation) Action {
+	return f(obs)
+}
+
+// SetController hands a being over to c. NPCs with a controller no longer
+// follow their behaviour. A nil controller hands the being back.
+func (world World) SetController(being *object.Character, c Controller) {
+	if c == nil {
+		delete(world.controllers, being)
+		return
+	}
+	world.controllers[being] = c
+}
+
+// Controller returns what controls a being, false if nothing does.
+func (world World) Controller(being *object.Character) (Controller, bool) {
+	c, ok := world.controllers[being]
+	return c, ok
+}
+
+// Roster returns the player followed by the NPCs in the order of their
+// indexes, the order bots are handed beings in.
+func (world World) Roster() []*object.Character {
+	roster := []*object.Character{world.Player}
+	for _, being := range world.sortedBeings() {
+		if being != world.Player {
+			roster = append(roster, being)
+		}
+	}
+	return roster
+}
+
+// Observe takes a copy of what a being can tell about the world around it.
+func (world World) Observe(being *object.Character) Observation {
+	location := *being.Location
+	cycle, _ := world.Cycle()
+	obs := Observation{
+		Tick:     *world.Time,
+		Cycle:    cycle,
+		Index:    being.Ident().Index,
+		Type:     being.Ident().Type,
+		Location: location,
+		Facing:   being.Direction,
+		Radius:   ObservationRadius,
+	}
+
+	obs.Light = Vision(location, world).Lumen
+	_, obs.Carrying = world.Carried(being)
+
+	// Sight reaches the corners of the square, only walls hide cells in it
+	corner := geometry.Distance(image.Point{}, image.Point{X: ObservationRadius, Y: ObservationRadius})
+	fov := world.FieldOfView(location, corner)
+	for y := -ObservationRadius; y <= ObservationRadius; y++ {
+		for x := -ObservationRadius; x <= ObservationRadius; x++ {
+			p := location.Add(image.Point{X: x, Y: y})
+			if !fov.Sees(p) {
+				obs.Cells = append(obs.Cells, Cell{Point: p})
+				continue
+			}
+			cell := Cell{Point: p, Passable: world.Geography.CanPass(p, being), Visible: true}
+			for _, thing := range world.Geography.At(p) {
+				cell.Things = append(cell.Things, thing.Ident().Type)
+			}
+			obs.Cells = append(obs.Cells, cell)
+		}
+	}
+	return obs
+}
+
+// control asks every controller what its being does and queues it. Waiting
+// needs nothing done so it isn't queued.
+func (world World) control() {
+	for _, being := range world.sortedBeings() {
+		c, ok := world.controllers[being]
+		if !ok {
+			continue
+		}
+		a := c.Act(world.Observe(being))
+		if a.Kind == ActionWait {
+			continue
+		}
+		a.Being = being
+		world.Enqueue(a)
+	}
+}
+
+// RandomWalk steps in a random direction that isn't blocked, the way NPCs
+// moved before they had behaviours.
+type RandomWalk struct {
+	rnd *rand.Rand
+}
+
+func NewRandomWalk(seed int64) *RandomWalk {
+	return &RandomWalk{rnd: rand.New(rand.NewSource(seed))}
+}
+
+func (r *RandomWalk) Act(obs Observation) Action {
+	direction, ok := randomStep(r.rnd, func(direction object.Direction) bool {
+		cell, ok := obs.Cell(obs.Location.Add(moveTransform[direction]))
+		return ok && cell.Passable
+	})
+	if !ok {
+		return Action{Kind: ActionWait}
+	}
+	return Action{Kind: ActionMove, Direction: direction}
+}
+
+// randomStep tries the directions in an order drawn from rnd and returns the
+// first one step accepts, false if none does.
+func randomStep(rnd *rand.Rand, step func(object.Direction) bool) (object.Direction, bool) {
+	directions := slices.Clone(directions)
+	rnd.Shuffle(len(directions), func(i, j int) { directions[i], directions[j] = directions[j], directions[i] })
+
+	for _, direction := range directions {
+		if step(direction) {
+			return direction, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,122 @@
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserve(t *testing.T) {
+	w := drawnWorld(t,
+		"@@@@@@@",
+		"@M  E @",
+		"@@@@@@@",
+		"@  @@@@",
+		"@@@@@@@",
+	)
+	enemy := drawnNPC(t, w)
+	obs := w.Observe(w.Player)
+	assert.Equal(t, image.Point{X: 1, Y: 1}, obs.Location)
+	assert.Equal(t, w.Player.Ident().Index, obs.Index)
+	assert.Equal(t, object.PlayerType, obs.Type)
+	assert.Len(t, obs.Cells, (ObservationRadius*2+1)*(ObservationRadius*2+1))
+
+	wall, ok := obs.Cell(image.Point{X: 0, Y: 1})
+	require.True(t, ok)
+	assert.False(t, wall.Passable)
+	assert.Equal(t, []object.ObjectType{object.ObstacleType}, wall.Things)
+
+	floor, _ := obs.Cell(image.Point{X: 2, Y: 1})
+	assert.True(t, floor.Passable)
+
+	npc, _ := obs.Cell(*enemy.Location)
+	assert.Contains(t, npc.Things, object.EnemyType, "Other beings can be seen")
+	assert.False(t, npc.Passable)
+
+	offMap, ok := obs.Cell(image.Point{X: -3, Y: -3})
+	assert.True(t, ok)
+	assert.False(t, offMap.Passable, "Cells off the map can't be passed")
+
+	hidden, _ := obs.Cell(image.Point{X: 1, Y: 3})
+	assert.False(t, hidden.Visible, "Cells behind walls can't be seen")
+	assert.Empty(t, hidden.Things)
+	assert.True(t, wall.Visible)
+
+	_, ok = obs.Cell(image.Point{X: 1 + ObservationRadius + 1, Y: 1})
+	assert.False(t, ok, "Cells past the radius are not observed")
+
+	obs.Cells[0].Passable = true
+	assert.False(t, w.Observe(w.Player).Cells[0].Passable, "Observations are copies")
+}
+
+func TestControllersDriveTheirBeings(t *testing.T) {
+	w := drawnWorld(t,
+		"@@@@@@@@@@",
+		"@M     E @",
+		"@@@@@@@@@@",
+	)
+	enemy := drawnNPC(t, w)
+	var seen []Observation
+	w.SetController(enemy, ControllerFunc(func(obs Observation) Action {
+		seen = append(seen, obs)
+		return MoveAction(nil, object.East)
+	}))
+
+	w.Tick()
+	w.NpcMove()
+	require.Len(t, seen, 1, "Controllers act once a tick")
+	assert.Equal(t, image.Point{X: 7, Y: 1}, seen[0].Location)
+	assert.Equal(t, image.Point{X: 8, Y: 1}, *enemy.Location, "Only the controller should move the NPC")
+
+	w.SetController(enemy, nil)
+	_, ok := w.Controller(enemy)
+	assert.False(t, ok)
+	w.Tick()
+	assert.Len(t, seen, 1, "Removed controllers aren't asked again")
+}
+
+func TestWaitingIsNotQueued(t *testing.T) {
+	w := drawnWorld(t,
+		"@@@@@@",
+		"@M  E@",
+		"@@@@@@",
+	)
+	var applied []Action
+	w.OnAction(func(_ int, a Action) { applied = append(applied, a) })
+	w.SetController(w.Player, ControllerFunc(func(Observation) Action { return Action{Kind: ActionWait} }))
+	w.Tick()
+	assert.Empty(t, applied)
+}
+
+func TestDespawnDropsTheController(t *testing.T) {
+	w := drawnWorld(t,
+		"@@@@@@",
+		"@M  E@",
+		"@@@@@@",
+	)
+	enemy := drawnNPC(t, w)
+	w.SetController(enemy, NewRandomWalk(1))
+	require.True(t, w.Despawn(enemy))
+	_, ok := w.Controller(enemy)
+	assert.False(t, ok)
+}
+
+func TestRandomWalk(t *testing.T) {
+	w := drawnWorld(t,
+		"@@@@@@@@@",
+		"@M     E@",
+		"@@@@@@@@@",
+	)
+	enemy := drawnNPC(t, w)
+	walk := NewRandomWalk(1)
+	for range 10 {
+		a := walk.Act(w.Observe(enemy))
+		assert.Equal(t, MoveAction(nil, object.West), a, "The only way out is west")
+	}
+
+	w.Geography.AddLoc(image.Point{X: 6, Y: 1}, object.NewObject(0, object.ObstacleType, false))
+	assert.Equal(t, ActionWait, walk.Act(w.Observe(enemy)).Kind, "Walkers that are boxed in wait")
+}
@@ -0,0 +1,147 @@
+// Package provides a rooms and corridors dungeon generator.
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"image"
+	"iter"
+)
+
+// DungeonGenerator carves rectangular rooms out of solid wall and joins each
+// room to the one before it with an L shaped corridor. Every room gets a torch
+// set into its wall, so the torches never block a passage.
+type DungeonGenerator struct {
+	// Rooms is how many rooms to try to place, overlapping ones are skipped.
+	Rooms   int
+	MinRoom int
+	MaxRoom int
+	Floor   object.Thing
+	Wall    object.Thing
+}
+
+func DefaultDungeon() DungeonGenerator {
+	return DungeonGenerator{
+		Rooms:   60,
+		MinRoom: 4,
+		MaxRoom: 12,
+		Floor:   object.NewObject(0, object.Dirt2Type, true),
+		Wall:    object.NewObject(0, object.ObstacleType, false),
+	}
+}
+
+func (dg DungeonGenerator) Generate(height, width int, cfg Config) (Map, object.Lights) {
+	rnd := cfg.Rand()
+
+	geography := make(Map, height)
+	for y := range geography {
+		geography[y] = make([]object.ThingList, width)
+		for x := range geography[y] {
+			geography[y][x] = object.ThingList{dg.Wall}
+		}
+	}
+	carve := func(p image.Point) {
+		geography.SetLoc(p, object.ThingList{dg.Floor})
+	}
+
+	var rooms []image.Rectangle
+	for range dg.Rooms {
+		w := dg.MinRoom + rnd.Intn(dg.MaxRoom-dg.MinRoom+1)
+		h := dg.MinRoom + rnd.Intn(dg.MaxRoom-dg.MinRoom+1)
+		if w+2 > width || h+2 > height {
+			continue
+		}
+		x := 1 + rnd.Intn(width-w-1)
+		y := 1 + rnd.Intn(height-h-1)
+		room := image.Rect(x, y, x+w, y+h)
+
+		// Keep a wall between rooms so they don't merge into odd shapes
+		if overlapsAny(room.Inset(-1), rooms) {
+			continue
+		}
+		for p := range points(room) {
+			carve(p)
+		}
+		if len(rooms) > 0 {
+			dg.corridor(centre(rooms[len(rooms)-1]), centre(room), rnd.Intn(2) == 0, carve)
+		}
+		rooms = append(rooms, room)
+	}
+
+	var lights object.Lights
+	for _, room := range rooms {
+		// The wall above the room's top left corner, or below its bottom right if
+		// a corridor has been cut through there
+		for _, torch := range []image.Point{{X: room.Min.X + 1, Y: room.Min.Y - 1}, {X: room.Max.X - 2, Y: room.Max.Y}} {
+			if geography.At(torch).Top().Ident() == dg.Wall.Ident() {
+				light := cfg.newTorch(torch)
+				geography.SetLoc(torch, object.ThingList{light})
+				lights = append(lights, light)
+				break
+			}
+		}
+	}
+
+	return geography, lights
+}
+
+// corridor carves from a to b, going across first or down first.
+func (dg DungeonGenerator) corridor(a, b image.Point, acrossFirst bool, carve func(image.Point)) {
+	corner := image.Point{X: b.X, Y: a.Y}
+	if !acrossFirst {
+		corner = image.Point{X: a.X, Y: b.Y}
+	}
+	for p := range line(a, corner) {
+		carve(p)
+	}
+	for p := range line(corner, b) {
+		carve(p)
+	}
+}
+
+func centre(r image.Rectangle) image.Point {
+	return image.Point{X: (r.Min.X + r.Max.X) / 2, Y: (r.Min.Y + r.Max.Y) / 2}
+}
+
+func overlapsAny(r image.Rectangle, others []image.Rectangle) bool {
+	for _, other := range others {
+		if r.Overlaps(other) {
+			return true
+		}
+	}
+	return false
+}
+
+// points yields every point inside r.
+func points(r image.Rectangle) iter.Seq[image.Point] {
+	return func(yield func(image.Point) bool) {
+		for y := r.Min.Y; y < r.Max.Y; y++ {
+			for x := r.Min.X; x < r.Max.X; x++ {
+				if !yield(image.Point{X: x, Y: y}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// line yields the points from a to b inclusive, which must share a row or column.
+func line(a, b image.Point) iter.Seq[image.Point] {
+	return func(yield func(image.Point) bool) {
+		step := image.Point{X: sign(b.X - a.X), Y: sign(b.Y - a.Y)}
+		for p := a; ; p = p.Add(step) {
+			if !yield(p) || p == b {
+				return
+			}
+		}
+	}
+}
+
+func sign(x int) int {
+	switch {
+	case x < 0:
+		return -1
+	case x > 0:
+		return 1
+	}
+	return 0
+}
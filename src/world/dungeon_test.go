@@ -0,0 +1,75 @@
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"image"
+	"slices"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// reachable counts the passable cells that can be walked to from start.
+func reachable(m Map, start image.Point) int {
+	seen := map[image.Point]bool{start: true}
+	queue := []image.Point{start}
+	for len(queue) > 0 {
+		p := queue[0]
+		queue = queue[1:]
+		for _, off := range []image.Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+			q := p.Add(off)
+			if !seen[q] && m.CanPass(q, nil) {
+				seen[q] = true
+				queue = append(queue, q)
+			}
+		}
+	}
+	return len(seen)
+}
+
+func TestDungeonRoomsAreConnected(t *testing.T) {
+	geography, _ := DefaultDungeon().Generate(60, 100, EmptyConfig().WithSeed(1))
+
+	floor := 0
+	var start image.Point
+	for y, row := range geography {
+		for x := range row {
+			if geography.CanPass(image.Point{X: x, Y: y}, nil) {
+				floor++
+				start = image.Point{X: x, Y: y}
+			}
+		}
+	}
+
+	assert.Greater(t, floor, 0, "Dungeon should have rooms")
+	assert.Equal(t, floor, reachable(geography, start), "Every room should be reachable from every other")
+}
+
+func TestDungeonTorchesLightRooms(t *testing.T) {
+	geography, lights := DefaultDungeon().Generate(60, 100, EmptyConfig().WithSeed(2))
+
+	assert.NotEmpty(t, lights, "Rooms should be lit")
+	for _, light := range lights {
+		assert.Equal(t, object.TorchType, geography.At(*light.Location).Top().Ident().Type, "Every light should be a torch")
+
+		beside := 0
+		for _, off := range []image.Point{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+			if geography.CanPass(light.Location.Add(off), nil) {
+				beside++
+			}
+		}
+		assert.GreaterOrEqual(t, beside, 1, "Torches should face onto the floor")
+	}
+}
+
+func TestDungeonIsDeterministic(t *testing.T) {
+	first, _ := DefaultDungeon().Generate(40, 60, EmptyConfig().WithSeed(3))
+	second, _ := DefaultDungeon().Generate(40, 60, EmptyConfig().WithSeed(3))
+	assert.Equal(t, first, second, "Same seed should produce the same dungeon")
+}
+
+func TestLine(t *testing.T) {
+	assert.Equal(t, []image.Point{{1, 2}, {2, 2}, {3, 2}}, slices.Collect(line(image.Point{1, 2}, image.Point{3, 2})))
+	assert.Equal(t, []image.Point{{4, 3}, {4, 2}}, slices.Collect(line(image.Point{4, 3}, image.Point{4, 2})))
+	assert.Equal(t, []image.Point{{5, 5}}, slices.Collect(line(image.Point{5, 5}, image.Point{5, 5})))
+}
@@ -0,0 +1,127 @@
+// Package provides field of view, the cells a being can see without anything
+// opaque in the way.
+package world
+
+import (
+	"gobotworld/src/geometry"
+	"gobotworld/src/world/object"
+	"image"
+)
+
+// SightRadius is how far the player can see.
+const SightRadius = 15
+
+// FieldOfView is the set of cells that can be seen from a point.
+type FieldOfView map[image.Point]bool
+
+// Sees reports whether p is in view.
+func (fov FieldOfView) Sees(p image.Point) bool {
+	return fov[p]
+}
+
+// octant maps the row and column scanned by castLight onto one eighth of the
+// map around the origin.
+type octant struct {
+	xx, xy, yx, yy int
+}
+
+var octants = []octant{
+	{1, 0, 0, 1},
+	{0, 1, 1, 0},
+	{0, -1, 1, 0},
+	{-1, 0, 0, 1},
+	{-1, 0, 0, -1},
+	{0, -1, -1, 0},
+	{0, 1, -1, 0},
+	{1, 0, 0, -1},
+}
+
+// FieldOfView returns the cells within radius of origin that can be seen from
+// it. Opaque cells are seen but hide what is behind them, and so do cells
+// that don't exist.
+func (world World) FieldOfView(origin image.Point, radius int) FieldOfView {
+	// Room for the square around the origin saves growing the map
+	fov := make(FieldOfView, (2*radius+1)*(2*radius+1))
+	fov[origin] = true
+	for _, o := range octants {
+		world.castLight(fov, origin, radius, 1, 1, 0, o)
+	}
+	return fov
+}
+
+// Visible returns what a being can see, the player as far as SightRadius and
+// NPCs as far as their sight.
+func (world World) Visible(being *object.Character) FieldOfView {
+	radius := SightRadius
+	if b, ok := world.behaviours[being]; ok {
+		radius = b.Sight
+	}
+	return world.FieldOfView(*being.Location, radius)
+}
+
+// CanSee reports whether to is within radius of from with nothing opaque in
+// between.
+func (world World) CanSee(from, to image.Point, radius int) bool {
+	if geometry.Distance(from, to) > radius {
+		return false
+	}
+	return world.FieldOfView(from, radius).Sees(to)
+}
+
+// castLight is recursive shadowcasting over one octant. Rows are scanned
+// outwards from row, keeping to the slopes between start and end, and every
+// run of opaque cells narrows the slopes the next rows can be seen through.
+func (world World) castLight(fov FieldOfView, origin image.Point, radius, row int, start, end float64, o octant) {
+	if start < end {
+		return
+	}
+	for j := row; j <= radius; j++ {
+		dy := -j
+		blocked := false
+		newStart := 0.0
+		for dx := -j; dx <= 0; dx++ {
+			left := (float64(dx) - 0.5) / (float64(dy) + 0.5)
+			right := (float64(dx) + 0.5) / (float64(dy) - 0.5)
+			if start < right {
+				continue
+			}
+			if end > left {
+				break
+			}
+
+			p := origin.Add(image.Point{X: dx*o.xx + dy*o.xy, Y: dx*o.yx + dy*o.yy})
+			if geometry.Distance(origin, p) <= radius {
+				fov[p] = true
+			}
+			opaque := world.opaque(p)
+			switch {
+			case blocked && opaque:
+				newStart = right
+			case blocked:
+				blocked = false
+				start = newStart
+			case opaque && j < radius:
+				blocked = true
+				world.castLight(fov, origin, radius, j+1, start, left, o)
+				newStart = right
+			}
+		}
+		if blocked {
+			return
+		}
+	}
+}
+
+// opaque reports whether p hides what is behind it.
+func (world World) opaque(p image.Point) bool {
+	things := world.Geography.At(p)
+	if things == nil {
+		return true
+	}
+	for _, thing := range things {
+		if thing.Ident().Type.Opaque() {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,90 @@
+package world
+
+import (
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// seen draws the cells in view over the map, '#' for cells that are seen.
+func seen(w World, fov FieldOfView) []string {
+	bounds := w.Geography.Bounds()
+	var rows []string
+	for y := bounds.Min.Y; y < bounds.Max.Y; y++ {
+		row := []byte{}
+		for x := bounds.Min.X; x < bounds.Max.X; x++ {
+			c := byte('.')
+			if fov.Sees(image.Point{X: x, Y: y}) {
+				c = '#'
+			}
+			row = append(row, c)
+		}
+		rows = append(rows, string(row))
+	}
+	return rows
+}
+
+func TestFieldOfViewInTheOpen(t *testing.T) {
+	w := drawnWorld(t,
+		"       ",
+		"       ",
+		"       ",
+		"   M   ",
+		"       ",
+		"       ",
+		"       ",
+	)
+	assert.Equal(t, []string{
+		"..###..",
+		".#####.",
+		"#######",
+		"#######",
+		"#######",
+		".#####.",
+		"..###..",
+	}, seen(w, w.FieldOfView(image.Point{X: 3, Y: 3}, 3)), "Sight reaches as far as the radius")
+}
+
+func TestWallsBlockTheView(t *testing.T) {
+	w := drawnWorld(t,
+		"@@@@@@@@@",
+		"@M  @   @",
+		"@   @   @",
+		"@       @",
+		"@@@@@@@@@",
+	)
+	assert.Equal(t, []string{
+		"#####....",
+		"#####....",
+		"#####....",
+		"######...",
+		"#######..",
+	}, seen(w, w.FieldOfView(image.Point{X: 1, Y: 1}, 10)), "Walls are seen but hide what is behind them")
+}
+
+func TestCanSee(t *testing.T) {
+	w := drawnWorld(t,
+		"@@@@@@@@@",
+		"@M  @ ^ @",
+		"@       @",
+		"@@@@@@@@@",
+	)
+	from := image.Point{X: 1, Y: 1}
+	assert.True(t, w.CanSee(from, image.Point{X: 3, Y: 2}, 5))
+	assert.False(t, w.CanSee(from, image.Point{X: 5, Y: 1}, 5), "The wall is in the way")
+	assert.False(t, w.CanSee(from, image.Point{X: 7, Y: 2}, 5), "Too far away")
+	assert.True(t, w.CanSee(image.Point{X: 7, Y: 2}, image.Point{X: 5, Y: 1}, 5), "Torches don't block sight")
+}
+
+func TestVisible(t *testing.T) {
+	w := drawnWorld(t,
+		"@@@@@@@@@@@@@@@@",
+		"@M            E@",
+		"@@@@@@@@@@@@@@@@",
+	)
+	enemy := w.Roster()[1]
+	far := image.Point{X: 14, Y: 1}
+	assert.True(t, w.Visible(w.Player).Sees(far), "The player sees down the corridor")
+	assert.False(t, w.Visible(enemy).Sees(*w.Player.Location), "NPCs only see as far as their sight")
+}
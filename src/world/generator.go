@@ -0,0 +1,52 @@
+// Package provides the interface for the different ways of generating a map.
+package world
+
+import "gobotworld/src/world/object"
+
+// Generator builds the terrain and lights of a new map. Randomness should be
+// drawn from cfg.Rand() so that maps are reproducible from the seed.
+type Generator interface {
+	Generate(height, width int, cfg Config) (Map, object.Lights)
+}
+
+// GeneratorFunc lets an ordinary function be used as a Generator.
+type GeneratorFunc func(height, width int, cfg Config) (Map, object.Lights)
+
+func (f GeneratorFunc) Generate(height, width int, cfg Config) (Map, object.Lights) {
+	return f(height, width, cfg)
+}
+
+// TileGenerator is a Generator that can also build its map straight into a
+// TileMap, so that a compact world never holds the whole map as a Map.
+type TileGenerator interface {
+	Generator
+	GenerateTiles(height, width int, cfg Config) (*TileMap, object.Lights)
+}
+
+// Weighted picks every cell independently from the config's terrain weights.
+// It is the generator used when the config doesn't name one.
+var Weighted Generator = weighted{}
+
+type weighted struct{}
+
+func (weighted) Generate(height, width int, cfg Config) (Map, object.Lights) {
+	return RandomMap(height, width, cfg)
+}
+
+func (weighted) GenerateTiles(height, width int, cfg Config) (*TileMap, object.Lights) {
+	return RandomTileMap(height, width, cfg)
+}
+
+// generate builds a map with generator, as a TileMap if the config asks for a
+// compact one. Generators that aren't TileGenerators build a Map first, which
+// is copied into the TileMap, so the whole Map is held for a while.
+func generate(generator Generator, height, width int, cfg Config) (Grid, object.Lights) {
+	if tg, ok := generator.(TileGenerator); ok && cfg.compact {
+		return tg.GenerateTiles(height, width, cfg)
+	}
+	geography, lights := generator.Generate(height, width, cfg)
+	if cfg.compact {
+		return CompactMap(geography), lights
+	}
+	return geography, lights
+}
@@ -0,0 +1,36 @@
+// Package provides the interface shared by the different kinds of map.
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"image"
+	"iter"
+)
+
+// Grid is the surface beings move over. Map is a Grid of fixed size and
+// ChunkedMap is one that grows as it is explored.
+type Grid interface {
+	At(p image.Point) object.ThingList
+	SetLoc(p image.Point, things object.ThingList)
+	AddLoc(p image.Point, thing object.Thing)
+	RemoveLoc(p image.Point, thing object.Thing)
+	CanPass(p image.Point, thing object.Thing) bool
+	// Bounds is the area cells can be in. Cells outside it never exist, cells
+	// inside it may not exist yet.
+	Bounds() image.Rectangle
+	// Cells visits every cell that exists right now, always in the same order.
+	Cells() iter.Seq2[image.Point, object.ThingList]
+}
+
+// streamer is a Grid that creates and drops cells depending on where the
+// beings are. The world calls Stream at the end of every tick and relights the
+// areas it returns, those of the cells it has created.
+type streamer interface {
+	Stream(focus []image.Point) []image.Rectangle
+}
+
+// lightSource is a Grid that brings its own lights, such as the torches of
+// the chunks it has generated.
+type lightSource interface {
+	Lights() object.Lights
+}
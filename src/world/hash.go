@@ -0,0 +1,41 @@
+// Package provides a fingerprint of the world state for comparing runs.
+package world
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"slices"
+)
+
+// Hash returns a digest of everything that makes up the simulation state: the
+// clock, every thing on the map, where each being is and which way it faces,
+// and the lights. Two worlds with the same hash are in the same state.
+func (world World) Hash() string {
+	h := sha256.New()
+	write := func(values ...int) {
+		var buf [8]byte
+		for _, v := range values {
+			binary.LittleEndian.PutUint64(buf[:], uint64(v))
+			h.Write(buf[:])
+		}
+	}
+
+	write(*world.Time)
+	for p, things := range world.Geography.Cells() {
+		write(p.X, p.Y, len(things))
+		for _, thing := range things {
+			write(thing.Ident().Index, int(thing.Ident().Type))
+		}
+	}
+	for _, being := range world.sortedBeings() {
+		write(being.Ident().Index, being.Location.X, being.Location.Y, int(being.Direction))
+	}
+	for _, light := range append(slices.Clip(*world.Lights), world.carried()...) {
+		c := light.Colour
+		write(light.Location.X, light.Location.Y, light.Radius, light.Intensity, int(c.R), int(c.G), int(c.B))
+		write(light.Flicker...)
+	}
+
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,188 @@
+// Package provides the light map, how brightly the lights of the world light
+// each cell, worked out once a tick.
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"image"
+	"image/color"
+)
+
+// LightMap keeps the cells each light reaches so that a tick only has to work
+// out the light around lights that have come, gone or changed, or whose
+// surroundings have. Light fades with distance, opaque things cast shadows and
+// where lights overlap they add up, to no more than object.MaxLumen, and their
+// colours mix.
+type LightMap struct {
+	reach map[*object.Light]*shine
+	glows map[image.Point]glow
+	// dirty holds the areas that have changed since the last update.
+	dirty []image.Rectangle
+	// casts counts the lights worked out by the last update.
+	casts int
+}
+
+// shine is what a light reaches, worked out from where it was with the
+// radius it had. Cells hold how brightly a light at full intensity lights
+// them, brightness is how bright it was at the last update.
+type shine struct {
+	from       image.Point
+	radius     int
+	brightness int
+	colour     color.RGBA
+	cells      map[image.Point]int
+}
+
+// glow is the light falling on a cell, along with the colours of the lights
+// each weighted by how brightly they light it.
+type glow struct {
+	lumen   int
+	r, g, b int
+}
+
+func newLightMap() *LightMap {
+	return &LightMap{
+		reach: map[*object.Light]*shine{},
+		glows: map[image.Point]glow{},
+	}
+}
+
+// Lumen returns how brightly p is lit, 0 when no light reaches it.
+func (lm *LightMap) Lumen(p image.Point) int {
+	return min(lm.glows[p].lumen, object.MaxLumen)
+}
+
+// Colour returns the colour of the light falling on p, the colours of the
+// lights that reach it mixed by how brightly each lights it. Cells no light
+// reaches are black.
+func (lm *LightMap) Colour(p image.Point) color.RGBA {
+	g, ok := lm.glows[p]
+	if !ok || g.lumen == 0 {
+		return color.RGBA{A: 0xFF}
+	}
+	return color.RGBA{R: uint8(g.r / g.lumen), G: uint8(g.g / g.lumen), B: uint8(g.b / g.lumen), A: 0xFF}
+}
+
+// Relight marks p as changed, such as a wall built or knocked down there. The
+// lights that reach it are worked out again on the next tick.
+func (world World) Relight(p image.Point) {
+	world.lighting.relight(image.Rectangle{Min: p, Max: p.Add(image.Point{X: 1, Y: 1})})
+}
+
+func (lm *LightMap) relight(area image.Rectangle) {
+	lm.dirty = append(lm.dirty, area)
+}
+
+// LightMap returns the light as it was at the end of the last tick. Lights
+// added to the world since show up after the next one.
+func (world World) LightMap() *LightMap {
+	return world.lighting
+}
+
+// update catches the light map up with the lights of the world. Lights that
+// have moved, changed radius or whose surroundings have changed are cast
+// again, those that have only flickered or changed colour are just added up
+// again.
+func (lm *LightMap) update(world World) {
+	current := map[*object.Light]bool{}
+	for _, light := range world.LightSources() {
+		current[light] = true
+	}
+
+	var changed []*shine
+	for light, s := range lm.reach {
+		if !current[light] || s.from != *light.Location || s.radius != light.Radius || lm.disturbed(s) {
+			changed = append(changed, s)
+			delete(lm.reach, light)
+		}
+	}
+	lm.dirty = lm.dirty[:0]
+	lm.casts = 0
+	for light := range current {
+		brightness := light.Brightness(*world.Time)
+		s, ok := lm.reach[light]
+		switch {
+		case !ok:
+			s = lm.cast(world, light)
+			lm.reach[light] = s
+			lm.casts++
+		case s.brightness == brightness && s.colour == light.Colour:
+			continue
+		}
+		s.brightness = brightness
+		s.colour = light.Colour
+		changed = append(changed, s)
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	// Every cell a changed light reached, or reaches now, is lit again from
+	// the lights near it
+	area := map[image.Point]bool{}
+	for _, s := range changed {
+		for y := -s.radius; y <= s.radius; y++ {
+			for x := -s.radius; x <= s.radius; x++ {
+				p := s.from.Add(image.Point{X: x, Y: y})
+				area[p] = true
+				delete(lm.glows, p)
+			}
+		}
+	}
+	for _, s := range lm.reach {
+		if !near(s, changed) {
+			continue
+		}
+		for p, full := range s.cells {
+			if !area[p] {
+				continue
+			}
+			lumen := full * s.brightness / object.MaxLumen
+			if lumen == 0 {
+				continue
+			}
+			g := lm.glows[p]
+			g.lumen += lumen
+			g.r += lumen * int(s.colour.R)
+			g.g += lumen * int(s.colour.G)
+			g.b += lumen * int(s.colour.B)
+			lm.glows[p] = g
+		}
+	}
+}
+
+// cast works out the cells a light reaches, those it can see.
+func (lm *LightMap) cast(world World, light *object.Light) *shine {
+	s := &shine{from: *light.Location, radius: light.Radius, cells: map[image.Point]int{}}
+	for p := range world.FieldOfView(s.from, s.radius) {
+		if lumen := object.LightAt(p, s.from, s.radius); lumen > 0 {
+			s.cells[p] = lumen
+		}
+	}
+	return s
+}
+
+// disturbed reports whether an area that has changed is within reach of a
+// light.
+func (lm *LightMap) disturbed(s *shine) bool {
+	reach := image.Rect(s.from.X-s.radius, s.from.Y-s.radius, s.from.X+s.radius+1, s.from.Y+s.radius+1)
+	for _, area := range lm.dirty {
+		if reach.Overlaps(area) {
+			return true
+		}
+	}
+	return false
+}
+
+// near reports whether a light is close enough to any of the others for
+// their light to overlap.
+func near(s *shine, others []*shine) bool {
+	for _, other := range others {
+		d := s.from.Sub(other.from)
+		reach := s.radius + other.radius
+		if max(d.X, -d.X) <= reach && max(d.Y, -d.Y) <= reach {
+			return true
+		}
+	}
+	return false
+}
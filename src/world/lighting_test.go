@@ -0,0 +1,183 @@
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"image"
+	"image/color"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// lit is the light at p worked out from every light that can see it, the way
+// the light map should.
+func lit(w World, p image.Point) int {
+	lumen := 0
+	for _, light := range w.LightSources() {
+		if w.CanSee(*light.Location, p, light.Radius) {
+			lumen += object.LightAt(p, *light.Location, light.Radius) * light.Brightness(*w.Time-1) / object.MaxLumen
+		}
+	}
+	return min(lumen, object.MaxLumen)
+}
+
+func assertLitLike(t *testing.T, w World, msg string) {
+	t.Helper()
+	for p := range w.Geography.Cells() {
+		if !assert.Equal(t, lit(w, p), w.LightMap().Lumen(p), "%s at %v", msg, p) {
+			return
+		}
+	}
+}
+
+func TestLightMap(t *testing.T) {
+	w := SeededWorld(log.New(io.Discard, "", 0), 6)
+	require.NotEmpty(t, *w.Lights)
+	assertLitLike(t, w, "The light map is made with the world")
+	assert.Equal(t, len(*w.Lights), w.lighting.casts)
+
+	w.Tick()
+	assert.Zero(t, w.lighting.casts, "Nothing is lit again when nothing changes")
+
+	added := object.NewLight(image.Point{X: 50, Y: 50}, w.TorchRadius())
+	*w.Lights = append(*w.Lights, added)
+	assert.Zero(t, w.LightMap().Lumen(added.Location.Add(image.Point{X: 2})), "The light map changes once a tick")
+	w.Tick()
+	assert.Equal(t, 1, w.lighting.casts, "Only the new light is worked out")
+	assertLitLike(t, w, "Added lights light the map")
+
+	// The added light and one the world was made with
+	*w.Lights = (*w.Lights)[:len(*w.Lights)-2]
+	w.Tick()
+	assert.Zero(t, w.lighting.casts)
+	assertLitLike(t, w, "Removed lights leave the dark behind")
+}
+
+func TestRelight(t *testing.T) {
+	w := InitWorld(log.New(io.Discard, "", 0), 30, 30, EmptyConfig().WithSeed(1))
+	*w.Lights = object.Lights{object.NewLight(image.Point{X: 5, Y: 5}, 4), object.NewLight(image.Point{X: 20, Y: 20}, 4)}
+	w.Tick()
+	require.Equal(t, 2, w.lighting.casts)
+
+	w.Relight(image.Point{X: 7, Y: 6})
+	w.Tick()
+	assert.Equal(t, 1, w.lighting.casts, "Only lights reaching a changed cell are worked out again")
+	assertLitLike(t, w, "Relit lights light the map the same")
+}
+
+func TestShadows(t *testing.T) {
+	w := drawnWorld(t,
+		"@@@@@@@@@@",
+		"@M       @",
+		"@   ^    @",
+		"@   @    @",
+		"@        @",
+		"@@@@@@@@@@",
+	)
+	lm := w.LightMap()
+	assert.Equal(t, object.MaxLumen, lm.Lumen(image.Point{X: 4, Y: 2}), "Brightest at the torch")
+	assert.Greater(t, lm.Lumen(image.Point{X: 4, Y: 1}), lm.Lumen(image.Point{X: 1, Y: 1}), "Light fades with distance")
+	assert.Positive(t, lm.Lumen(image.Point{X: 4, Y: 3}), "The side of the wall facing the torch is lit")
+	assert.Zero(t, lm.Lumen(image.Point{X: 4, Y: 4}), "The wall casts a shadow")
+	assert.Positive(t, lm.Lumen(image.Point{X: 2, Y: 4}))
+}
+
+func TestLightsAddUp(t *testing.T) {
+	w := InitWorld(log.New(io.Discard, "", 0), 30, 30, EmptyConfig().WithSeed(1))
+	a, b := object.NewLight(image.Point{X: 10, Y: 10}, 4), object.NewLight(image.Point{X: 14, Y: 10}, 4)
+	between := image.Point{X: 12, Y: 10}
+	*w.Lights = object.Lights{a}
+	w.Tick()
+	one := w.LightMap().Lumen(between)
+	require.Positive(t, one)
+
+	*w.Lights = object.Lights{a, b}
+	w.Tick()
+	assert.Equal(t, min(2*one, object.MaxLumen), w.LightMap().Lumen(between), "Overlapping lights add up")
+	assert.Equal(t, object.MaxLumen, w.LightMap().Lumen(*a.Location), "But no brighter than MaxLumen")
+}
+
+func TestLightRadius(t *testing.T) {
+	w := InitWorld(log.New(io.Discard, "", 0), 30, 30, EmptyConfig().WithSeed(1))
+	small, large := object.NewLight(image.Point{X: 5, Y: 5}, 2), object.NewLight(image.Point{X: 20, Y: 20}, 6)
+	*w.Lights = object.Lights{small, large}
+	w.Tick()
+	assert.Zero(t, w.LightMap().Lumen(image.Point{X: 8, Y: 5}), "Each light reaches as far as its own radius")
+	assert.Positive(t, w.LightMap().Lumen(image.Point{X: 25, Y: 20}))
+	assertLitLike(t, w, "Lights of different sizes")
+
+	small.Radius = 4
+	w.Tick()
+	assert.Equal(t, 1, w.lighting.casts, "Lights that change size are worked out again")
+	assert.Positive(t, w.LightMap().Lumen(image.Point{X: 8, Y: 5}))
+}
+
+func TestIntensityAndFlicker(t *testing.T) {
+	w := InitWorld(log.New(io.Discard, "", 0), 30, 30, EmptyConfig().WithSeed(1))
+	light := object.NewLight(image.Point{X: 10, Y: 10}, 4)
+	light.Intensity = object.MaxLumen / 2
+	*w.Lights = object.Lights{light}
+	w.Tick()
+	assert.Equal(t, object.MaxLumen/2, w.LightMap().Lumen(*light.Location), "Dim lights are dim at the source")
+
+	light.Intensity = object.MaxLumen
+	light.Flicker = []int{100, 25}
+	var lumens []int
+	for range 4 {
+		w.Tick()
+		assert.Zero(t, w.lighting.casts, "Flickering lights don't need working out again")
+		assertLitLike(t, w, "Flickering lights")
+		lumens = append(lumens, w.LightMap().Lumen(*light.Location))
+	}
+	assert.Equal(t, []int{object.MaxLumen / 4, object.MaxLumen, object.MaxLumen / 4, object.MaxLumen}, lumens)
+}
+
+func TestLightColours(t *testing.T) {
+	w := InitWorld(log.New(io.Discard, "", 0), 30, 30, EmptyConfig().WithSeed(1))
+	red, blue := object.NewLight(image.Point{X: 10, Y: 10}, 4), object.NewLight(image.Point{X: 14, Y: 10}, 4)
+	red.Colour = color.RGBA{R: 0xFF, A: 0xFF}
+	blue.Colour = color.RGBA{B: 0xFF, A: 0xFF}
+	*w.Lights = object.Lights{red, blue}
+	w.Tick()
+
+	lm := w.LightMap()
+	assert.Equal(t, red.Colour, lm.Colour(image.Point{X: 7, Y: 10}), "Cells one light reaches take its colour")
+	mixed := lm.Colour(image.Point{X: 12, Y: 10})
+	assert.Equal(t, mixed.R, mixed.B, "Cells lit evenly by two lights are an even mix")
+	assert.Positive(t, mixed.R)
+	toward := lm.Colour(image.Point{X: 11, Y: 10})
+	assert.Greater(t, toward.R, toward.B, "The nearer light colours a cell more")
+	assert.Equal(t, color.RGBA{A: 0xFF}, lm.Colour(image.Point{X: 25, Y: 25}), "Dark cells are black")
+
+	blue.Colour = color.RGBA{G: 0xFF, A: 0xFF}
+	w.Tick()
+	assert.Zero(t, w.lighting.casts)
+	assert.Positive(t, lm.Colour(image.Point{X: 12, Y: 10}).G, "Changing colour shows on the next tick")
+}
+
+func TestNewChunksAreLit(t *testing.T) {
+	cm, _ := newFlatChunks(8, 1)
+	w := InitChunkedWorld(log.New(io.Discard, "", 0), cm, NewConfig().WithSeed(1).WithNPCs())
+	for range 20 {
+		w.Enqueue(MoveAction(w.Player, object.East))
+		w.Tick()
+	}
+	assertLitLike(t, w, "Lights shine into chunks loaded after them")
+}
+
+func TestVision(t *testing.T) {
+	w := InitWorld(log.New(io.Discard, "", 0), 30, 30, EmptyConfig().WithSeed(1))
+	light := object.NewLight(image.Point{X: 5, Y: 5}, 4)
+	*w.Lights = object.Lights{light}
+	w.Tick()
+
+	block := Vision(image.Point{X: 7, Y: 5}, w)
+	assert.Equal(t, object.LightAt(image.Point{X: 7, Y: 5}, *light.Location, 4), block.Lumen)
+	assert.Equal(t, object.TorchColour, block.Colour)
+	cycle, _ := w.Cycle()
+	assert.Equal(t, cycle, block.Time)
+	assert.Zero(t, Vision(image.Point{X: 20, Y: 20}, w).Lumen, "Cells out of reach are dark")
+}
@@ -0,0 +1,110 @@
+// Package provides the memory beings keep of the parts of the map they have seen.
+package world
+
+import (
+	"encoding/json"
+	"gobotworld/src/world/object"
+	"image"
+	"maps"
+	"slices"
+)
+
+// Memory is what a being remembers of the map, the type of the thing on top
+// of every cell it has seen as it was when it last saw it. Beings aren't
+// remembered, they don't stay put.
+type Memory map[image.Point]object.ObjectType
+
+// Recall returns what was last seen at p, false if p has never been seen.
+func (m Memory) Recall(p image.Point) (object.ObjectType, bool) {
+	t, ok := m[p]
+	return t, ok
+}
+
+// MarshalJSON writes the memory as [x, y, type] triples in the order of the
+// rows, JSON objects can't have points for keys.
+func (m Memory) MarshalJSON() ([]byte, error) {
+	points := slices.SortedFunc(maps.Keys(m), func(a, b image.Point) int {
+		if a.Y != b.Y {
+			return a.Y - b.Y
+		}
+		return a.X - b.X
+	})
+	cells := make([][3]int, 0, len(points))
+	for _, p := range points {
+		cells = append(cells, [3]int{p.X, p.Y, int(m[p])})
+	}
+	return json.Marshal(cells)
+}
+
+func (m *Memory) UnmarshalJSON(data []byte) error {
+	var cells [][3]int
+	if err := json.Unmarshal(data, &cells); err != nil {
+		return err
+	}
+	*m = make(Memory, len(cells))
+	for _, c := range cells {
+		(*m)[image.Point{X: c[0], Y: c[1]}] = object.ObjectType(c[2])
+	}
+	return nil
+}
+
+// memory is a being's Memory along with where it last looked from.
+type memory struct {
+	cells Memory
+	from  *image.Point
+}
+
+// Memory returns what a being remembers of the map, nil if it hasn't seen
+// anything yet. It is the being's own memory, not a copy, and is only to be
+// read.
+func (world World) Memory(being *object.Character) Memory {
+	if m, ok := world.memories[being]; ok {
+		return m.cells
+	}
+	return nil
+}
+
+// remember adds what the player and the beings with a controller can see to
+// their memories. NPCs following their behaviour have no use for one. Beings
+// that haven't moved since they last looked see nothing new.
+func (world World) remember() {
+	for _, being := range world.sortedBeings() {
+		if _, controlled := world.controllers[being]; being != world.Player && !controlled {
+			continue
+		}
+		m, ok := world.memories[being]
+		if !ok {
+			m = &memory{cells: Memory{}}
+			world.memories[being] = m
+		}
+		if m.from != nil && *m.from == *being.Location {
+			continue
+		}
+		for p := range world.Visible(being) {
+			if t, ok := terrainAt(world.Geography.At(p)); ok {
+				m.cells[p] = t
+			}
+		}
+		from := *being.Location
+		m.from = &from
+	}
+}
+
+// lookAgain has the beings that remember look about them on the next tick even
+// if they haven't moved, something they may see has changed.
+func (world World) lookAgain() {
+	for _, m := range world.memories {
+		m.from = nil
+	}
+}
+
+// terrainAt returns the type of the last thing in a cell that isn't a being,
+// false if there is nothing else there.
+func terrainAt(things object.ThingList) (object.ObjectType, bool) {
+	for i := len(things) - 1; i >= 0; i-- {
+		if _, ok := things[i].(*object.Character); !ok {
+			return things[i].Ident().Type, true
+		}
+	}
+	return 0, false
+}
@@ -0,0 +1,71 @@
+package world
+
+import (
+	"encoding/json"
+	"gobotworld/src/world/object"
+	"image"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRemember(t *testing.T) {
+	w := drawnWorld(t,
+		"@@@@@@@@@",
+		"@M  @   @",
+		"@   @ E @",
+		"@@@@@@@@@",
+	)
+	assert.Nil(t, w.Memory(w.Player), "Nothing is remembered before the world ticks")
+
+	w.Tick()
+	memory := w.Memory(w.Player)
+	wall, ok := memory.Recall(image.Point{X: 4, Y: 1})
+	assert.True(t, ok)
+	assert.Equal(t, object.ObstacleType, wall)
+	_, ok = memory.Recall(image.Point{X: 6, Y: 1})
+	assert.False(t, ok, "Cells behind the wall haven't been seen")
+	here, _ := memory.Recall(*w.Player.Location)
+	assert.NotEqual(t, object.PlayerType, here, "Beings aren't remembered, what is under them is")
+
+	w.Enqueue(MoveAction(w.Player, object.South))
+	w.Enqueue(MoveAction(w.Player, object.East))
+	w.Tick()
+	for _, p := range []image.Point{{X: 4, Y: 1}, {X: 1, Y: 1}} {
+		_, ok := w.Memory(w.Player).Recall(p)
+		assert.True(t, ok, "%v stays remembered", p)
+	}
+}
+
+func TestControlledBeingsRemember(t *testing.T) {
+	w := drawnWorld(t,
+		"@@@@@@@@@@@@@@",
+		"@M          E@",
+		"@@@@@@@@@@@@@@",
+	)
+	enemy := w.Roster()[1]
+	w.Tick()
+	assert.Nil(t, w.Memory(enemy), "NPCs following their behaviour don't remember")
+
+	w.SetController(enemy, ControllerFunc(func(Observation) Action { return WaitAction(nil) }))
+	w.Tick()
+	_, ok := w.Memory(enemy).Recall(image.Point{X: 5, Y: 1})
+	assert.True(t, ok)
+	_, ok = w.Memory(enemy).Recall(image.Point{X: 2, Y: 1})
+	assert.False(t, ok, "NPCs only remember as far as they can see")
+
+	w.Despawn(enemy)
+	assert.Nil(t, w.Memory(enemy), "Despawned beings forget")
+}
+
+func TestMemoryJSON(t *testing.T) {
+	memory := Memory{{X: 3, Y: 1}: object.RockType, {X: 1, Y: 2}: object.ObstacleType, {X: 0, Y: 1}: object.Dirt2Type}
+	data, err := json.Marshal(memory)
+	require.NoError(t, err)
+	assert.JSONEq(t, `[[0,1,1],[3,1,2],[1,2,3]]`, string(data), "Cells are written row by row")
+
+	var read Memory
+	require.NoError(t, json.Unmarshal(data, &read))
+	assert.Equal(t, memory, read)
+}
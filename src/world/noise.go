@@ -0,0 +1,182 @@
+// Package provides a coherent noise terrain generator.
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"image"
+	"math"
+	"math/rand"
+)
+
+// perlin is classic 2D gradient noise. The permutation table is drawn from
+// the world's random source so each seed gives a different landscape.
+type perlin struct {
+	perm [512]int
+}
+
+func newPerlin(rnd *rand.Rand) *perlin {
+	p := &perlin{}
+	order := rnd.Perm(256)
+	for i := range p.perm {
+		p.perm[i] = order[i&255]
+	}
+	return p
+}
+
+func fade(t float64) float64 {
+	return t * t * t * (t*(t*6-15) + 10)
+}
+
+func lerp(t, a, b float64) float64 {
+	return a + t*(b-a)
+}
+
+// grad picks one of eight gradient directions from the hash and returns its
+// dot product with (x, y).
+func grad(hash int, x, y float64) float64 {
+	switch hash & 7 {
+	case 0:
+		return x + y
+	case 1:
+		return -x + y
+	case 2:
+		return x - y
+	case 3:
+		return -x - y
+	case 4:
+		return x
+	case 5:
+		return -x
+	case 6:
+		return y
+	default:
+		return -y
+	}
+}
+
+// at returns the noise at (x, y), roughly in the range -1 to 1.
+func (p *perlin) at(x, y float64) float64 {
+	fx, fy := math.Floor(x), math.Floor(y)
+	xi, yi := int(fx)&255, int(fy)&255
+	xf, yf := x-fx, y-fy
+	u, v := fade(xf), fade(yf)
+
+	aa := p.perm[p.perm[xi]+yi]
+	ab := p.perm[p.perm[xi]+yi+1]
+	ba := p.perm[p.perm[xi+1]+yi]
+	bb := p.perm[p.perm[xi+1]+yi+1]
+
+	x1 := lerp(u, grad(aa, xf, yf), grad(ba, xf-1, yf))
+	x2 := lerp(u, grad(ab, xf, yf-1), grad(bb, xf-1, yf-1))
+	return lerp(v, x1, x2)
+}
+
+// fractal layers octaves of noise, each at twice the frequency and half the
+// strength of the one before, and scales the result to the range 0 to 1.
+func (p *perlin) fractal(x, y float64, octaves int) float64 {
+	total, amplitude, frequency, norm := 0.0, 1.0, 1.0, 0.0
+	for range octaves {
+		total += p.at(x*frequency, y*frequency) * amplitude
+		norm += amplitude
+		amplitude /= 2
+		frequency *= 2
+	}
+	return math.Max(0, math.Min(1, (total/norm+1)/2))
+}
+
+// NoiseBand maps noise values below Below to a terrain type. Bands are checked
+// in order so they should be sorted by Below.
+type NoiseBand struct {
+	Below float64
+	Type  object.Thing
+}
+
+// NoiseGenerator builds landscapes from coherent noise, so neighbouring cells
+// tend to share a terrain type and obstacles form ridges instead of static.
+type NoiseGenerator struct {
+	// Scale is roughly how many cells one hill of the lowest octave spans.
+	Scale   float64
+	Octaves int
+	Bands   []NoiseBand
+	// TorchChance is the chance of a passable cell holding a torch.
+	TorchChance float64
+}
+
+// DefaultNoise is a landscape of dirt with patches of rock and rocky ridges.
+func DefaultNoise() NoiseGenerator {
+	return NoiseGenerator{
+		Scale:   24,
+		Octaves: 4,
+		Bands: []NoiseBand{
+			{0.5, object.NewObject(0, object.Dirt1Type, true)},
+			{0.6, object.NewObject(0, object.Dirt2Type, true)},
+			{0.66, object.NewObject(0, object.RockType, true)},
+			{math.Inf(1), object.NewObject(0, object.ObstacleType, false)},
+		},
+		TorchChance: 1.0 / 700,
+	}
+}
+
+func (ng NoiseGenerator) Generate(height, width int, cfg Config) (Map, object.Lights) {
+	noise := newPerlin(cfg.Rand())
+	rnd := cfg.Rand()
+
+	geography := make(Map, height)
+	var lights object.Lights
+	for y := range geography {
+		geography[y] = make([]object.ThingList, width)
+		for x := range geography[y] {
+			thing := ng.band(noise.fractal(float64(x)/ng.Scale, float64(y)/ng.Scale, ng.Octaves))
+			if thing.Passable(nil) && rnd.Float64() < ng.TorchChance {
+				light := cfg.newTorch(image.Point{X: x, Y: y})
+				thing = light
+				lights = append(lights, light)
+			}
+			geography[y][x] = object.ThingList{thing}
+		}
+	}
+	return geography, lights
+}
+
+func (ng NoiseGenerator) band(value float64) object.Thing {
+	for _, band := range ng.Bands {
+		if value < band.Below {
+			return band.Type
+		}
+	}
+	return ng.Bands[len(ng.Bands)-1].Type
+}
+
+// Chunks returns a ChunkGenerator that samples the same landscape as Generate
+// over an unbounded area. Torches are placed from the seed and the cell alone
+// so that a chunk comes out the same whenever it is generated, each is a copy
+// of torch.
+func (ng NoiseGenerator) Chunks(seed int64, torch object.Light) ChunkGenerator {
+	return noiseChunks{ng: ng, seed: seed, torch: torch, noise: newPerlin(rand.New(rand.NewSource(seed)))}
+}
+
+type noiseChunks struct {
+	ng    NoiseGenerator
+	seed  int64
+	torch object.Light
+	noise *perlin
+}
+
+func (nc noiseChunks) GenerateChunk(area image.Rectangle) (Map, object.Lights) {
+	cells := make(Map, area.Dy())
+	var lights object.Lights
+	for y := range cells {
+		cells[y] = make([]object.ThingList, area.Dx())
+		for x := range cells[y] {
+			p := area.Min.Add(image.Point{X: x, Y: y})
+			thing := nc.ng.band(nc.noise.fractal(float64(p.X)/nc.ng.Scale, float64(p.Y)/nc.ng.Scale, nc.ng.Octaves))
+			if thing.Passable(nil) && cellChance(nc.seed, p) < nc.ng.TorchChance {
+				light := nc.torch.CopyAt(p)
+				thing = light
+				lights = append(lights, light)
+			}
+			cells[y][x] = object.ThingList{thing}
+		}
+	}
+	return cells, lights
+}
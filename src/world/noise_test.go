@@ -0,0 +1,101 @@
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"image"
+	"io"
+	"log"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPerlinRange(t *testing.T) {
+	noise := newPerlin(rand.New(rand.NewSource(1)))
+	for y := 0.0; y < 20; y += 0.37 {
+		for x := 0.0; x < 20; x += 0.41 {
+			v := noise.fractal(x, y, 4)
+			assert.GreaterOrEqual(t, v, 0.0, "Fractal noise should not go below 0")
+			assert.LessOrEqual(t, v, 1.0, "Fractal noise should not go above 1")
+		}
+	}
+	assert.Equal(t, 0.0, noise.at(3, 7), "Noise is zero on lattice points")
+}
+
+func TestNoiseGeneratorIsDeterministic(t *testing.T) {
+	first, firstLights := DefaultNoise().Generate(50, 60, EmptyConfig().WithSeed(3))
+	second, secondLights := DefaultNoise().Generate(50, 60, EmptyConfig().WithSeed(3))
+
+	assert.Equal(t, first, second, "Same seed should produce the same landscape")
+	assert.Equal(t, firstLights, secondLights, "Same seed should produce the same torches")
+	assert.Equal(t, 50, first.Height())
+	assert.Equal(t, 60, first.Width())
+}
+
+// sameNeighbours is the fraction of horizontally adjacent cells that share a type.
+func sameNeighbours(m Map) float64 {
+	same, total := 0, 0
+	for _, row := range m {
+		for x := 1; x < len(row); x++ {
+			total++
+			if row[x][0].Ident().Type == row[x-1][0].Ident().Type {
+				same++
+			}
+		}
+	}
+	return float64(same) / float64(total)
+}
+
+func TestNoiseIsCoherent(t *testing.T) {
+	noise, _ := DefaultNoise().Generate(100, 100, DefaultConfig().WithSeed(4))
+	weighted, _ := RandomMap(100, 100, DefaultConfig().WithSeed(4))
+
+	assert.Greater(t, sameNeighbours(noise), 0.8, "Noise terrain should come in patches")
+	assert.Greater(t, sameNeighbours(noise), sameNeighbours(weighted)+0.2, "Noise terrain should be more coherent than weighted terrain")
+}
+
+func TestNoiseBands(t *testing.T) {
+	ng := DefaultNoise()
+	assert.Equal(t, object.Dirt1Type, ng.band(0.1).Ident().Type)
+	assert.Equal(t, object.Dirt2Type, ng.band(0.55).Ident().Type)
+	assert.Equal(t, object.RockType, ng.band(0.65).Ident().Type)
+	assert.Equal(t, object.ObstacleType, ng.band(0.9).Ident().Type)
+
+	ng.Bands = ng.Bands[:1]
+	ng.Bands[0].Below = 0.2
+	assert.Equal(t, object.Dirt1Type, ng.band(math.Inf(1)).Ident().Type, "Values past the last band should use it")
+}
+
+func TestNoiseTorchesAreLights(t *testing.T) {
+	ng := DefaultNoise()
+	ng.TorchChance = 0.05
+	geography, lights := ng.Generate(40, 40, EmptyConfig().WithSeed(6))
+
+	assert.NotEmpty(t, lights, "Torches should be placed")
+	for _, light := range lights {
+		assert.Equal(t, object.TorchType, geography.At(*light.Location).Top().Ident().Type, "Every light should be a torch")
+	}
+}
+
+func TestInitWorldUsesGenerator(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	called := false
+	flat := GeneratorFunc(func(height, width int, cfg Config) (Map, object.Lights) {
+		called = true
+		geography := make(Map, height)
+		for y := range geography {
+			geography[y] = make([]object.ThingList, width)
+			for x := range geography[y] {
+				geography[y][x] = object.ThingList{object.NewObject(0, object.Dirt2Type, true)}
+			}
+		}
+		return geography, nil
+	})
+
+	w := InitWorld(logger, 30, 30, EmptyConfig().WithGenerator(flat))
+
+	assert.True(t, called, "InitWorld should use the configured generator")
+	assert.Equal(t, object.Dirt2Type, w.Geography.At(image.Point{X: 1, Y: 1}).Top().Ident().Type)
+}
@@ -55,10 +55,21 @@ func newCharacter(id int, t ObjectType, start image.Point) *Character {
 	return &c
 }
 
+// NewCharacter creates a being of type t. Every being in a world should have
+// its own id, see IDs.
+func NewCharacter(id int, t ObjectType, start image.Point) *Character {
+	return newCharacter(id, t, start)
+}
+
+// NewPlayer creates a player with the fixed index 99, for worlds with only a
+// few hand placed beings.
 func NewPlayer(start image.Point) *Character {
 	return newCharacter(99, PlayerType, start)
 }
 
+// NewNPC creates an enemy with the fixed index 20. Two NPCs created this way
+// are the same being as far as the world is concerned, use NewCharacter for
+// anything more.
 func NewNPC(start image.Point) *Character {
 	return newCharacter(20, EnemyType, start)
 }
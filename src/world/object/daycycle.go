@@ -1,19 +1,22 @@
 package object
 
-import (
-	"gobotworld/src/geometry"
-	"image"
-)
+import "image/color"
 
 const (
 	ticksPerCount   = 4
 	countPerDay     = 20
 	countPerHalfDay = countPerDay / 2
+
+	// DefaultDayLength is the number of ticks in a full day and night.
+	DefaultDayLength = ticksPerCount * countPerDay
 )
 
 type LightBlock struct {
 	Time  DayCycle
 	Lumen int
+	// Colour is the colour of the light falling on the cell, the colours of
+	// the lights that reach it mixed by how brightly each lights it.
+	Colour color.RGBA
 }
 
 type DayCycle int
@@ -24,62 +27,18 @@ const (
 )
 
 func Time(time int) (DayCycle, int) {
-	now := (time / ticksPerCount) % countPerDay
+	return TimeOfDay(time, DefaultDayLength)
+}
+
+// TimeOfDay is Time for a day that lasts dayLength ticks instead of the default.
+// The day is split into its counts exactly, so days that aren't a multiple of
+// the count still last dayLength ticks.
+func TimeOfDay(time int, dayLength int) (DayCycle, int) {
+	dayLength = max(dayLength, 1)
+	now := (time % dayLength) * countPerDay / dayLength
 	cycle := DayTime
 	if now > countPerHalfDay {
 		cycle = NightTime
 	}
 	return cycle, now % countPerHalfDay
 }
-
-type Light struct {
-	ident Object
-	Area  int
-}
-
-func NewLight(area int) Light {
-	return Light{ident: Object{10, TorchType}, Area: area}
-}
-
-func (lt Light) Ident() Object {
-	return lt.ident
-}
-
-func (lt Light) Passable(_o Thing) bool {
-	return true
-}
-
-func LightAt(origin image.Point, target image.Point, area int) int {
-	distance := geometry.Distance(origin, target)
-	lumen := 0
-	if distance <= area {
-		lumen = distance
-	}
-	return lumen
-}
-
-type Lights []*image.Point
-
-func (lts Lights) NearestLight(p image.Point) image.Point {
-	if len(lts) == 0 {
-		return image.Point{X: -1, Y: -1} // Indicate no lights are available
-	}
-
-	var nearest image.Point
-	var nearestDist = 0 // math.MaxInt
-	found := false
-	for _, light := range lts {
-		d := geometry.Distance(*light, p)
-
-		if d < nearestDist {
-			nearest = *light
-			nearestDist = d
-			found = true
-		}
-	}
-
-	if !found {
-		return image.Point{X: 0, Y: 0} // No light found
-	}
-	return nearest
-}
@@ -0,0 +1,159 @@
+package object
+
+import (
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color"
+	"reflect"
+)
+
+// ThingRecord is the on-disk form of a Thing. Kind names the concrete type the
+// data was encoded from so that it can be restored through the type registry.
+type ThingRecord struct {
+	Kind string          `json:"kind"`
+	Data json.RawMessage `json:"data"`
+}
+
+type thingDecoder func(data json.RawMessage) (Thing, error)
+
+var (
+	thingDecoders = map[string]thingDecoder{}
+	thingKinds    = map[reflect.Type]string{}
+)
+
+// RegisterThing makes a concrete Thing type available to EncodeThing and
+// DecodeThing. Sample is any value of the type, decode rebuilds one from the
+// JSON it marshals to.
+func RegisterThing(kind string, sample Thing, decode func(data json.RawMessage) (Thing, error)) {
+	if _, ok := thingDecoders[kind]; ok {
+		panic("thing kind registered twice: " + kind)
+	}
+	thingDecoders[kind] = decode
+	thingKinds[reflect.TypeOf(sample)] = kind
+}
+
+func EncodeThing(t Thing) (ThingRecord, error) {
+	kind, ok := thingKinds[reflect.TypeOf(t)]
+	if !ok {
+		return ThingRecord{}, fmt.Errorf("thing type %T is not registered", t)
+	}
+	data, err := json.Marshal(t)
+	if err != nil {
+		return ThingRecord{}, fmt.Errorf("encoding %s: %w", kind, err)
+	}
+	return ThingRecord{Kind: kind, Data: data}, nil
+}
+
+func DecodeThing(record ThingRecord) (Thing, error) {
+	decode, ok := thingDecoders[record.Kind]
+	if !ok {
+		return nil, fmt.Errorf("unknown thing kind %q", record.Kind)
+	}
+	thing, err := decode(record.Data)
+	if err != nil {
+		return nil, fmt.Errorf("decoding %s: %w", record.Kind, err)
+	}
+	return thing, nil
+}
+
+func init() {
+	RegisterThing("basic", BasicObject{}, func(data json.RawMessage) (Thing, error) {
+		var bo BasicObject
+		err := json.Unmarshal(data, &bo)
+		return bo, err
+	})
+	RegisterThing("character", &Character{}, func(data json.RawMessage) (Thing, error) {
+		ch := &Character{}
+		err := json.Unmarshal(data, ch)
+		return ch, err
+	})
+	RegisterThing("light", &Light{}, func(data json.RawMessage) (Thing, error) {
+		lt := &Light{}
+		err := json.Unmarshal(data, lt)
+		return lt, err
+	})
+}
+
+type basicObjectState struct {
+	Index    int        `json:"index"`
+	Type     ObjectType `json:"type"`
+	Passable bool       `json:"passable"`
+}
+
+func (bo BasicObject) MarshalJSON() ([]byte, error) {
+	return json.Marshal(basicObjectState{Index: bo.ident.Index, Type: bo.ident.Type, Passable: bo.passable})
+}
+
+func (bo *BasicObject) UnmarshalJSON(data []byte) error {
+	var state basicObjectState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	*bo = NewObject(state.Index, state.Type, state.Passable)
+	return nil
+}
+
+type characterState struct {
+	Index     int         `json:"index"`
+	Type      ObjectType  `json:"type"`
+	Location  image.Point `json:"location"`
+	Direction Direction   `json:"direction"`
+}
+
+func (ch *Character) MarshalJSON() ([]byte, error) {
+	return json.Marshal(characterState{
+		Index:     ch.ident.Index,
+		Type:      ch.ident.Type,
+		Location:  *ch.Location,
+		Direction: ch.Direction,
+	})
+}
+
+func (ch *Character) UnmarshalJSON(data []byte) error {
+	var state characterState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	*ch = *newCharacter(state.Index, state.Type, state.Location)
+	ch.Direction = state.Direction
+	return nil
+}
+
+type lightState struct {
+	Index     int         `json:"index"`
+	Type      ObjectType  `json:"type"`
+	Location  image.Point `json:"location"`
+	Radius    int         `json:"radius"`
+	Colour    [3]uint8    `json:"colour"`
+	Intensity int         `json:"intensity"`
+	Flicker   []int       `json:"flicker,omitempty"`
+}
+
+func (lt *Light) MarshalJSON() ([]byte, error) {
+	return json.Marshal(lightState{
+		Index:     lt.ident.Index,
+		Type:      lt.ident.Type,
+		Location:  *lt.Location,
+		Radius:    lt.Radius,
+		Colour:    [3]uint8{lt.Colour.R, lt.Colour.G, lt.Colour.B},
+		Intensity: lt.Intensity,
+		Flicker:   lt.Flicker,
+	})
+}
+
+func (lt *Light) UnmarshalJSON(data []byte) error {
+	var state lightState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return err
+	}
+	*lt = Light{
+		ident:     Object{state.Index, state.Type},
+		Location:  &state.Location,
+		Radius:    state.Radius,
+		Colour:    color.RGBA{R: state.Colour[0], G: state.Colour[1], B: state.Colour[2], A: 0xFF},
+		Intensity: state.Intensity,
+		Flicker:   state.Flicker,
+	}
+	return nil
+}
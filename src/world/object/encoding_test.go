@@ -0,0 +1,59 @@
+package object_test
+
+import (
+	"gobotworld/src/world/object"
+	"image"
+	"image/color"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEncodeDecodeBasicObject(t *testing.T) {
+	obj := object.NewObject(3, object.ObstacleType, false)
+
+	record, err := object.EncodeThing(obj)
+	assert.NoError(t, err)
+	assert.Equal(t, "basic", record.Kind, "BasicObject should be registered as basic")
+
+	decoded, err := object.DecodeThing(record)
+	assert.NoError(t, err)
+	assert.Equal(t, obj, decoded, "Decoded object should match the original")
+}
+
+func TestEncodeDecodeCharacter(t *testing.T) {
+	char := object.NewNPC(image.Point{X: 4, Y: 7})
+	char.Direction = object.West
+
+	record, err := object.EncodeThing(char)
+	assert.NoError(t, err)
+	assert.Equal(t, "character", record.Kind, "Character should be registered as character")
+
+	decoded, err := object.DecodeThing(record)
+	assert.NoError(t, err)
+	assert.Equal(t, char, decoded, "Decoded character should match the original")
+}
+
+func TestEncodeDecodeLight(t *testing.T) {
+	light := object.NewLight(image.Point{X: 3, Y: 4}, 6)
+	light.Colour = color.RGBA{R: 0x20, G: 0x40, B: 0xFF, A: 0xFF}
+	light.Intensity = 9
+	light.Flicker = []int{100, 80, 90}
+
+	record, err := object.EncodeThing(light)
+	assert.NoError(t, err)
+
+	decoded, err := object.DecodeThing(record)
+	assert.NoError(t, err)
+	assert.Equal(t, light, decoded, "Decoded light should match the original")
+}
+
+type unregistered struct{ object.BasicObject }
+
+func TestEncodeUnregisteredThing(t *testing.T) {
+	_, err := object.EncodeThing(unregistered{})
+	assert.Error(t, err, "Unregistered types should not encode")
+
+	_, err = object.DecodeThing(object.ThingRecord{Kind: "nope"})
+	assert.Error(t, err, "Unknown kinds should not decode")
+}
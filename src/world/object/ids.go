@@ -0,0 +1,40 @@
+package object
+
+import "sync"
+
+// IDs hands out the indexes of new things. An index is never handed out twice,
+// so a being spawned after another has gone can't be mistaken for it.
+type IDs struct {
+	mu   sync.Mutex
+	next int
+}
+
+// NewIDs starts handing out indexes from first. Index 0 is what terrain uses,
+// so things that should be drawn over it start from 1.
+func NewIDs(first int) *IDs {
+	return &IDs{next: first}
+}
+
+// Next returns an index that hasn't been handed out before.
+func (ids *IDs) Next() int {
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+	id := ids.next
+	ids.next++
+	return id
+}
+
+// Peek returns the index Next will hand out.
+func (ids *IDs) Peek() int {
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+	return ids.next
+}
+
+// Reserve makes sure index is never handed out, for things created elsewhere
+// such as those read from a save.
+func (ids *IDs) Reserve(index int) {
+	ids.mu.Lock()
+	defer ids.mu.Unlock()
+	ids.next = max(ids.next, index+1)
+}
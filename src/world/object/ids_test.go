@@ -0,0 +1,45 @@
+package object_test
+
+import (
+	"gobotworld/src/world/object"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIDs(t *testing.T) {
+	ids := object.NewIDs(1)
+	assert.Equal(t, 1, ids.Next())
+	assert.Equal(t, 2, ids.Next())
+
+	ids.Reserve(10)
+	assert.Equal(t, 11, ids.Peek(), "Reserved indexes should be skipped")
+	ids.Reserve(3)
+	assert.Equal(t, 11, ids.Next(), "Reserving an old index should not go backwards")
+}
+
+func TestIDsAreUniqueAcrossGoroutines(t *testing.T) {
+	ids := object.NewIDs(1)
+	seen := sync.Map{}
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range 100 {
+				_, dup := seen.LoadOrStore(ids.Next(), true)
+				assert.False(t, dup, "Every index should be handed out once")
+			}
+		}()
+	}
+	wg.Wait()
+	assert.Equal(t, 801, ids.Peek())
+}
+
+func TestIsNPC(t *testing.T) {
+	assert.True(t, object.EnemyType.IsNPC())
+	assert.True(t, object.CritterType.IsNPC())
+	assert.False(t, object.PlayerType.IsNPC())
+	assert.False(t, object.TorchType.IsNPC())
+}
@@ -0,0 +1,120 @@
+package object
+
+import (
+	"gobotworld/src/geometry"
+	"image"
+	"image/color"
+	"slices"
+)
+
+// MaxLumen is how brightly a cell is lit right by a light, no cell is lit
+// brighter however many lights reach it.
+const MaxLumen = 12
+
+// TorchColour is the warm orange of a burning torch, LanternColour the paler
+// light of a lantern.
+var (
+	TorchColour   = color.RGBA{R: 0xFA, G: 0x90, B: 0x20, A: 0xFF}
+	LanternColour = color.RGBA{R: 0xFF, G: 0xE0, B: 0xA0, A: 0xFF}
+)
+
+// Light is a source of light, placed on the map as a torch or carried about
+// by a being. Nothing can pass through one on the map.
+type Light struct {
+	ident    Object
+	Location *image.Point
+	// Radius is how far the light reaches.
+	Radius int
+	Colour color.RGBA
+	// Intensity is how brightly the light lights its own cell, up to MaxLumen.
+	Intensity int
+	// Flicker scales Intensity by a percentage that changes every tick, going
+	// round the list. Lights without one burn steadily.
+	Flicker []int
+}
+
+// NewLight creates a torch at location that reaches radius cells, burning
+// steadily at full intensity.
+func NewLight(location image.Point, radius int) *Light {
+	return &Light{
+		ident:     Object{0, TorchType},
+		Location:  &location,
+		Radius:    radius,
+		Colour:    TorchColour,
+		Intensity: MaxLumen,
+	}
+}
+
+// NewLantern creates a light to be carried that reaches radius cells. It has
+// no location until it is given to a being to carry.
+func NewLantern(radius int) *Light {
+	return &Light{
+		ident:     Object{0, TorchType},
+		Radius:    radius,
+		Colour:    LanternColour,
+		Intensity: MaxLumen,
+	}
+}
+
+// CopyAt returns a light like this one at location, for making many alike.
+func (lt *Light) CopyAt(location image.Point) *Light {
+	light := *lt
+	light.Location = &location
+	light.Flicker = slices.Clone(lt.Flicker)
+	return &light
+}
+
+func (lt *Light) Ident() Object {
+	return lt.ident
+}
+
+func (lt *Light) Passable(_o Thing) bool {
+	return false
+}
+
+// Brightness returns the intensity of the light at the given tick, after
+// flicker. It is kept between 0 and MaxLumen whatever the light was set to.
+func (lt *Light) Brightness(time int) int {
+	brightness := lt.Intensity
+	if len(lt.Flicker) > 0 {
+		brightness = brightness * lt.Flicker[time%len(lt.Flicker)] / 100
+	}
+	return min(max(brightness, 0), MaxLumen)
+}
+
+// LightAt returns how brightly a light at target with the given area lights
+// origin. It is MaxLumen at the light and fades evenly to nothing just past
+// the edge of the area.
+func LightAt(origin image.Point, target image.Point, area int) int {
+	distance := geometry.Distance(origin, target)
+	if distance > area {
+		return 0
+	}
+	return MaxLumen * (area + 1 - distance) / (area + 1)
+}
+
+type Lights []*Light
+
+func (lts Lights) NearestLight(p image.Point) image.Point {
+	if len(lts) == 0 {
+		return image.Point{X: -1, Y: -1} // Indicate no lights are available
+	}
+
+	var nearest image.Point
+	var nearestDist = 0 // math.MaxInt
+	found := false
+	for _, light := range lts {
+		d := geometry.Distance(*light.Location, p)
+
+		if d < nearestDist {
+			nearest = *light.Location
+			nearestDist = d
+			found = true
+		}
+	}
+
+	if !found {
+		return image.Point{X: 0, Y: 0} // No light found
+	}
+	return nearest
+}
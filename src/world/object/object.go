@@ -2,6 +2,7 @@
 package object
 
 import (
+	"fmt"
 	"slices"
 )
 
@@ -17,8 +18,48 @@ const (
 	PlayerType   = ObjectType(4)
 	EnemyType    = ObjectType(5)
 	TorchType    = ObjectType(6)
+	CritterType  = ObjectType(7)
 )
 
+var objectTypeNames = map[ObjectType]string{
+	Dirt1Type:    "dirt1",
+	Dirt2Type:    "dirt2",
+	RockType:     "rock",
+	ObstacleType: "obstacle",
+	PlayerType:   "player",
+	EnemyType:    "enemy",
+	TorchType:    "torch",
+	CritterType:  "critter",
+}
+
+// String method for ObjectType, the names are the ones used in config files
+func (t ObjectType) String() string {
+	if name, ok := objectTypeNames[t]; ok {
+		return name
+	}
+	return "unknown"
+}
+
+// IsNPC reports whether the type is one of the beings the world moves about.
+func (t ObjectType) IsNPC() bool {
+	return t == EnemyType || t == CritterType
+}
+
+// Opaque reports whether things of the type block sight.
+func (t ObjectType) Opaque() bool {
+	return t == ObstacleType
+}
+
+// ParseObjectType is the reverse of ObjectType.String.
+func ParseObjectType(name string) (ObjectType, error) {
+	for t, n := range objectTypeNames {
+		if n == name {
+			return t, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown object type %q", name)
+}
+
 type Thing interface {
 	Ident() Object
 	Passable(o Thing) bool
@@ -46,11 +46,40 @@ func TestThingListTop(t *testing.T) {
 }
 
 func TestNewLight(t *testing.T) {
-	light := object.NewLight(10)
+	light := object.NewLight(image.Point{X: 2, Y: 3}, 10)
 
 	assert.Equal(t, object.TorchType, light.Ident().Type, "Light type should be TorchType")
-	assert.True(t, light.Passable(nil), "Light should always be passable")
-	assert.Equal(t, 10, light.Area, "Light area should be 10")
+	assert.False(t, light.Passable(nil), "Lights block the way like the torches they are")
+	assert.Equal(t, 10, light.Radius, "Light radius should be 10")
+	assert.Equal(t, image.Point{X: 2, Y: 3}, *light.Location)
+	assert.Equal(t, object.MaxLumen, light.Intensity)
+}
+
+func TestBrightness(t *testing.T) {
+	light := object.NewLight(image.Point{}, 4)
+	assert.Equal(t, object.MaxLumen, light.Brightness(7), "Lights without flicker burn steadily")
+
+	light.Flicker = []int{100, 50}
+	assert.Equal(t, object.MaxLumen, light.Brightness(0))
+	assert.Equal(t, object.MaxLumen/2, light.Brightness(1))
+	assert.Equal(t, object.MaxLumen, light.Brightness(2), "Flicker goes round")
+
+	light.Flicker = []int{-50, 300}
+	assert.Zero(t, light.Brightness(0), "Lights never take light away")
+	assert.Equal(t, object.MaxLumen, light.Brightness(1), "Lights are never brighter than MaxLumen")
+}
+
+func TestCopyAt(t *testing.T) {
+	torch := object.NewLight(image.Point{}, 3)
+	torch.Flicker = []int{100, 70}
+	copied := torch.CopyAt(image.Point{X: 4, Y: 5})
+
+	assert.Equal(t, image.Point{X: 4, Y: 5}, *copied.Location)
+	assert.Equal(t, image.Point{}, *torch.Location, "The original stays where it was")
+	assert.Equal(t, torch.Radius, copied.Radius)
+	assert.Equal(t, torch.Ident(), copied.Ident())
+	copied.Flicker[0] = 10
+	assert.Equal(t, 100, torch.Flicker[0], "Copies flicker on their own")
 }
 
 func TestLightAt(t *testing.T) {
@@ -58,8 +87,11 @@ func TestLightAt(t *testing.T) {
 	target := image.Point{X: 6, Y: 6}
 	area := 3
 
+	assert.Equal(t, object.MaxLumen, object.LightAt(origin, origin, area), "Lumen should be brightest at the light")
+
 	lumen := object.LightAt(origin, target, area)
-	assert.Equal(t, 1, lumen, "Lumen should equal distance when within area")
+	assert.Equal(t, 9, lumen, "Lumen should fade with distance within area")
+	assert.Greater(t, lumen, object.LightAt(origin, image.Point{X: 8, Y: 5}, area), "Lumen should be dimmer further away")
 
 	targetOutside := image.Point{X: 10, Y: 10}
 	lumen = object.LightAt(origin, targetOutside, area)
@@ -73,3 +105,40 @@ func TestBasicObjectPassable(t *testing.T) {
 	obj = object.NewObject(2, object.PlayerType, true)
 	assert.True(t, obj.Passable(nil), "Passable should be true for this object")
 }
+
+func TestObjectTypeNames(t *testing.T) {
+	for _, objType := range []object.ObjectType{object.Dirt1Type, object.Dirt2Type, object.RockType, object.ObstacleType, object.PlayerType, object.EnemyType, object.TorchType, object.CritterType} {
+		parsed, err := object.ParseObjectType(objType.String())
+		assert.NoError(t, err)
+		assert.Equal(t, objType, parsed, "ParseObjectType should reverse String for %s", objType)
+	}
+
+	assert.Equal(t, "unknown", object.ObjectType(99).String())
+	_, err := object.ParseObjectType("lava")
+	assert.Error(t, err, "Unknown names should not parse")
+}
+
+func TestTimeOfDay(t *testing.T) {
+	cycle, count := object.TimeOfDay(0, 40)
+	assert.Equal(t, object.DayTime, cycle, "A day starts in daylight")
+	assert.Equal(t, 0, count)
+
+	cycle, _ = object.TimeOfDay(23, 40)
+	assert.Equal(t, object.NightTime, cycle, "A 40 tick day should be dark after 22 ticks")
+
+	cycle, _ = object.TimeOfDay(23, object.DefaultDayLength)
+	assert.Equal(t, object.DayTime, cycle, "The default day is still light at tick 23")
+
+	for _, dayLength := range []int{10, 30, 50} {
+		cycle, _ = object.TimeOfDay(dayLength-1, dayLength)
+		assert.Equal(t, object.NightTime, cycle, "A %d tick day ends in the dark", dayLength)
+		cycle, count = object.TimeOfDay(dayLength, dayLength)
+		assert.Equal(t, object.DayTime, cycle, "A %d tick day lasts %d ticks", dayLength, dayLength)
+		assert.Equal(t, 0, count)
+	}
+
+	first, firstCount := object.Time(45)
+	second, secondCount := object.TimeOfDay(45, object.DefaultDayLength)
+	assert.Equal(t, first, second, "Time should use the default day length")
+	assert.Equal(t, firstCount, secondCount)
+}
@@ -3,6 +3,7 @@ package world
 
 import (
 	"image"
+	"iter"
 	"log"
 
 	"github.com/fzipp/astar"
@@ -24,6 +25,39 @@ func (pf PathFinder) Find(start, dest image.Point) map[image.Point]bool {
 	return path
 }
 
+// Route returns the cells from start to dest in the order they are walked,
+// start first, or nil if dest can't be reached without straying more than
+// limit steps from start. Unlike Find, dest may hold a being, so Route can be
+// used to walk up to one. A limit of 0 means no limit.
+func (pf PathFinder) Route(start, dest image.Point, limit int) []image.Point {
+	graph := routeGraph{world: pf.World, start: start, dest: dest, limit: limit}
+	return astar.FindPath[image.Point](graph, start, dest, manhattanDistance, manhattanDistance)
+}
+
+type routeGraph struct {
+	world       World
+	start, dest image.Point
+	limit       int
+}
+
+func (g routeGraph) Neighbours(p image.Point) iter.Seq[image.Point] {
+	return func(yield func(image.Point) bool) {
+		for _, off := range compass {
+			q := p.Add(off)
+			if g.limit > 0 && manhattanDistance(g.start, q) > float64(g.limit) {
+				continue
+			}
+			passable := g.world.Geography.CanPass(q, nil)
+			if q == g.dest {
+				passable = terrainPassable(g.world.Geography, q)
+			}
+			if passable && !yield(q) {
+				return
+			}
+		}
+	}
+}
+
 func manhattanDistance(p, q image.Point) float64 {
 	return float64(abs(p.X-q.X) + abs(p.Y-q.Y))
 }
@@ -0,0 +1,51 @@
+// Package provides a random source that can be saved and restored.
+package world
+
+import (
+	"image"
+	"math/rand"
+)
+
+// countingSource wraps the standard source and counts how many values have
+// been drawn from it, which together with the seed is enough to restore it.
+type countingSource struct {
+	src   rand.Source64
+	draws uint64
+}
+
+func newCountingSource(seed int64, draws uint64) *countingSource {
+	s := &countingSource{src: rand.NewSource(seed).(rand.Source64)}
+	for s.draws < draws {
+		s.Uint64()
+	}
+	return s
+}
+
+func (s *countingSource) Int63() int64 {
+	s.draws++
+	return s.src.Int63()
+}
+
+func (s *countingSource) Uint64() uint64 {
+	s.draws++
+	return s.src.Uint64()
+}
+
+func (s *countingSource) Seed(seed int64) {
+	s.src.Seed(seed)
+	s.draws = 0
+}
+
+// cellChance returns a number in [0, 1) that depends only on the seed and the
+// cell. Generators that make cells in no particular order use it instead of a
+// shared source so the same cell always comes out the same.
+func cellChance(seed int64, p image.Point) float64 {
+	h := uint64(seed) ^ uint64(int64(p.X))*0x9e3779b97f4a7c15 ^ uint64(int64(p.Y))*0xc2b2ae3d27d4eb4f
+	// splitmix64 finaliser
+	h ^= h >> 30
+	h *= 0xbf58476d1ce4e5b9
+	h ^= h >> 27
+	h *= 0x94d049bb133111eb
+	h ^= h >> 31
+	return float64(h>>11) / (1 << 53)
+}
@@ -0,0 +1,263 @@
+// Package provides saving and loading of the complete world state.
+package world
+
+import (
+	"encoding/json"
+	"fmt"
+	"gobotworld/src/world/object"
+	"image"
+	"io"
+	"log"
+	"math/rand"
+	"os"
+)
+
+// SaveVersion is written into every save file. Load refuses files written
+// with a version it does not know how to read.
+const SaveVersion = 1
+
+type saveFile struct {
+	Version int    `json:"version"`
+	Seed    int64  `json:"seed"`
+	Draws   uint64 `json:"draws"`
+	Time    int    `json:"time"`
+	// DayLength is how many ticks a day lasts and TorchRadius how far the
+	// light of a torch reaches, zero for the default.
+	DayLength   int `json:"dayLength,omitempty"`
+	TorchRadius int `json:"torchRadius,omitempty"`
+	// Compact worlds are loaded back onto a TileMap.
+	Compact  bool                 `json:"compact,omitempty"`
+	NextID   int                  `json:"nextId,omitempty"`
+	Spawning []SpawnRule          `json:"spawning,omitempty"`
+	Palette  []object.ThingRecord `json:"palette"`
+	Cells    [][][]int            `json:"cells"`
+	Beings   []beingRecord        `json:"beings"`
+	// Lights are where the lights of the world are, in order. The lights
+	// themselves are saved on the map, saves written when torches were plain
+	// objects get lights of the torch radius in their place.
+	Lights []image.Point `json:"lights"`
+}
+
+type beingRecord struct {
+	Character  object.ThingRecord `json:"character"`
+	Player     bool               `json:"player"`
+	Controlled bool               `json:"controlled"`
+	Behaviour  *Behaviour         `json:"behaviour,omitempty"`
+	Memory     Memory             `json:"memory,omitempty"`
+	Lantern    *object.Light      `json:"lantern,omitempty"`
+}
+
+// Save writes the world to w. Beings are written once in their own section and
+// placed back on the map by Load, everything else on the map is written through
+// the object type registry. Queued actions and controllers are not saved.
+func (world World) Save(w io.Writer) error {
+	bounds := world.Geography.Bounds()
+	if bounds.Min != (image.Point{}) {
+		return fmt.Errorf("cannot save a world on a %T, only maps starting at (0, 0) can be saved", world.Geography)
+	}
+	_, compact := world.Geography.(*TileMap)
+	file := saveFile{
+		Version:     SaveVersion,
+		Seed:        world.seed,
+		Time:        *world.Time,
+		DayLength:   world.dayLength,
+		TorchRadius: world.torchRadius,
+		Cells:       make([][][]int, bounds.Dy()),
+		Compact:     compact,
+		NextID:      world.ids.Peek(),
+		Spawning:    world.spawning,
+	}
+	if world.src != nil {
+		file.Draws = world.src.draws
+	}
+
+	palette := map[string]int{}
+	for y := range file.Cells {
+		file.Cells[y] = make([][]int, bounds.Dx())
+	}
+	for p, things := range world.Geography.Cells() {
+		cell := make([]int, 0, len(things))
+		for _, thing := range things {
+			if ch, ok := thing.(*object.Character); ok {
+				if _, ok := world.Beings[ch]; ok {
+					continue
+				}
+			}
+			record, err := object.EncodeThing(thing)
+			if err != nil {
+				return fmt.Errorf("cell (%d, %d): %w", p.X, p.Y, err)
+			}
+			key := record.Kind + string(record.Data)
+			idx, ok := palette[key]
+			if !ok {
+				idx = len(file.Palette)
+				palette[key] = idx
+				file.Palette = append(file.Palette, record)
+			}
+			cell = append(cell, idx)
+		}
+		file.Cells[p.Y][p.X] = cell
+	}
+
+	for _, being := range world.sortedBeings() {
+		record, err := object.EncodeThing(being)
+		if err != nil {
+			return err
+		}
+		file.Beings = append(file.Beings, beingRecord{
+			Character:  record,
+			Player:     being == world.Player,
+			Controlled: world.Beings[being],
+			Behaviour:  world.behaviours[being],
+			Memory:     world.Memory(being),
+			Lantern:    world.lanterns[being],
+		})
+	}
+
+	for _, light := range *world.Lights {
+		file.Lights = append(file.Lights, *light.Location)
+	}
+
+	return json.NewEncoder(w).Encode(file)
+}
+
+// Load restores a world written by Save, including the state of its random
+// source so that the simulation carries on exactly as it would have.
+func Load(logger *log.Logger, r io.Reader) (World, error) {
+	var file saveFile
+	if err := json.NewDecoder(r).Decode(&file); err != nil {
+		return World{}, fmt.Errorf("reading save: %w", err)
+	}
+	if file.Version != SaveVersion {
+		return World{}, fmt.Errorf("unsupported save version %d, expected %d", file.Version, SaveVersion)
+	}
+
+	palette := make([]object.Thing, len(file.Palette))
+	for i, record := range file.Palette {
+		thing, err := object.DecodeThing(record)
+		if err != nil {
+			return World{}, err
+		}
+		palette[i] = thing
+	}
+
+	// Compact maps are loaded straight into a TileMap, without building the
+	// whole map as a Map first
+	var grid Grid
+	if file.Compact {
+		width := 0
+		if len(file.Cells) > 0 {
+			width = len(file.Cells[0])
+		}
+		grid = newTileMap(width, len(file.Cells))
+	} else {
+		geography := make(Map, len(file.Cells))
+		for y, row := range file.Cells {
+			geography[y] = make([]object.ThingList, len(row))
+		}
+		grid = geography
+	}
+	for y, row := range file.Cells {
+		for x, cell := range row {
+			things := make(object.ThingList, 0, len(cell))
+			for _, idx := range cell {
+				if idx < 0 || idx >= len(palette) {
+					return World{}, fmt.Errorf("cell (%d, %d): palette index %d out of range", x, y, idx)
+				}
+				things = append(things, palette[idx])
+			}
+			grid.SetLoc(image.Point{X: x, Y: y}, things)
+		}
+	}
+
+	world := World{
+		logger:      logger,
+		Geography:   grid,
+		Beings:      map[*object.Character]bool{},
+		Time:        &file.Time,
+		seed:        file.Seed,
+		src:         newCountingSource(file.Seed, file.Draws),
+		actions:     &actionQueue{},
+		dayLength:   file.DayLength,
+		torchRadius: file.TorchRadius,
+		ids:         object.NewIDs(max(file.NextID, 1)),
+		spawning:    file.Spawning,
+		behaviours:  map[*object.Character]*Behaviour{},
+		controllers: map[*object.Character]Controller{},
+		memories:    map[*object.Character]*memory{},
+		lanterns:    map[*object.Character]*object.Light{},
+		Lights:      &object.Lights{},
+	}
+	world.rnd = rand.New(world.src)
+	if world.dayLength == 0 {
+		world.dayLength = object.DefaultDayLength
+	}
+	if world.torchRadius == 0 {
+		world.torchRadius = object.TorchArea
+	}
+
+	for _, record := range file.Beings {
+		thing, err := object.DecodeThing(record.Character)
+		if err != nil {
+			return World{}, err
+		}
+		being, ok := thing.(*object.Character)
+		if !ok {
+			return World{}, fmt.Errorf("being of kind %q is not a character", record.Character.Kind)
+		}
+		if grid.At(*being.Location) == nil {
+			return World{}, fmt.Errorf("being %d is off the map at %v", being.Ident().Index, *being.Location)
+		}
+		grid.AddLoc(*being.Location, being)
+		world.ids.Reserve(being.Ident().Index)
+		world.Beings[being] = record.Controlled
+		if record.Behaviour != nil {
+			world.behaviours[being] = record.Behaviour
+		}
+		if record.Memory != nil {
+			world.memories[being] = &memory{cells: record.Memory}
+		}
+		if record.Lantern != nil {
+			world.Carry(being, record.Lantern)
+		}
+		if record.Player {
+			world.Player = being
+		}
+	}
+	if world.Player == nil {
+		return World{}, fmt.Errorf("save has no player")
+	}
+
+	for _, p := range file.Lights {
+		light, ok := lightIn(grid.At(p))
+		if !ok {
+			return World{}, fmt.Errorf("save has no light at %v", p)
+		}
+		*world.Lights = append(*world.Lights, light)
+	}
+	world.lighting = newLightMap()
+	world.lighting.update(world)
+
+	return world, nil
+}
+
+func (world World) SaveFile(path string) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := world.Save(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+func LoadFile(logger *log.Logger, path string) (World, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return World{}, err
+	}
+	defer file.Close()
+	return Load(logger, file)
+}
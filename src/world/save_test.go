@@ -0,0 +1,108 @@
+package world
+
+import (
+	"bytes"
+	"encoding/json"
+	"gobotworld/src/world/object"
+	"image"
+	"io"
+	"log"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSaveLoadRoundTrip(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	original := SeededWorld(logger, 3)
+	for i := 0; i < 25; i++ {
+		original.Enqueue(MoveAction(original.Player, object.East))
+		original.Tick()
+		original.NpcMove()
+	}
+
+	var buf bytes.Buffer
+	require.NoError(t, original.Save(&buf))
+	restored, err := Load(logger, &buf)
+	require.NoError(t, err)
+
+	assert.Equal(t, original.Geography, restored.Geography, "Map should be restored exactly")
+	assert.Equal(t, original.Lights, restored.Lights, "Lights should be restored")
+	assert.Equal(t, *original.Time, *restored.Time, "Time should be restored")
+	assert.Equal(t, original.Seed(), restored.Seed(), "Seed should be restored")
+	assert.Equal(t, *original.Player.Location, *restored.Player.Location, "Player should be restored")
+	assert.Equal(t, restored.Player, restored.Geography.At(*restored.Player.Location)[1], "Player should be back on the map")
+	assert.Len(t, restored.Beings, len(original.Beings), "Every being should be restored")
+	assert.Equal(t, original.Memory(original.Player), restored.Memory(restored.Player), "What the player has seen should be restored")
+
+	// Both worlds should carry on identically, which needs the random source restored too
+	for i := 0; i < 25; i++ {
+		original.Tick()
+		original.NpcMove()
+		restored.Tick()
+		restored.NpcMove()
+	}
+	originalBeings, restoredBeings := original.sortedBeings(), restored.sortedBeings()
+	for i := range originalBeings {
+		assert.Equal(t, *originalBeings[i].Location, *restoredBeings[i].Location, "Beings should keep moving the same way after loading")
+	}
+}
+
+func TestSaveKeepsSpawning(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	rule := SpawnRule{Type: object.CritterType, Day: 2, Every: 1}
+	original := InitWorld(logger, 30, 30, EmptyConfig().WithSeed(4).WithSpawnRules(rule))
+	original.Tick()
+
+	var buf bytes.Buffer
+	require.NoError(t, original.Save(&buf))
+	restored, err := Load(logger, &buf)
+	require.NoError(t, err)
+
+	original.Tick()
+	restored.Tick()
+	assert.Equal(t, original.Hash(), restored.Hash(), "Loaded worlds should keep spawning the same way")
+	assert.Equal(t, original.ids.Peek(), restored.ids.Peek(), "Indexes should carry on where they left off")
+}
+
+func TestSaveLoadFile(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	original := SeededWorld(logger, 4)
+	path := filepath.Join(t.TempDir(), "world.json")
+
+	require.NoError(t, original.SaveFile(path))
+	restored, err := LoadFile(logger, path)
+	require.NoError(t, err)
+
+	assert.Equal(t, original.Geography, restored.Geography, "Map should be restored from file")
+}
+
+func TestLoadRejectsUnknownVersion(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	data, _ := json.Marshal(saveFile{Version: SaveVersion + 1})
+
+	_, err := Load(logger, bytes.NewReader(data))
+	assert.ErrorContains(t, err, "unsupported save version", "Load should refuse newer formats")
+}
+
+func TestLoadNeedsItsLights(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	original := drawnWorld(t,
+		"@@@@@",
+		"@M ^@",
+		"@@@@@",
+	)
+	var buf bytes.Buffer
+	require.NoError(t, original.Save(&buf))
+
+	var file saveFile
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &file))
+	file.Lights = append(file.Lights, image.Point{X: 2, Y: 1})
+	data, err := json.Marshal(file)
+	require.NoError(t, err)
+
+	_, err = Load(logger, bytes.NewReader(data))
+	assert.ErrorContains(t, err, "no light at (2,1)")
+}
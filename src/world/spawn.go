@@ -0,0 +1,123 @@
+// Package provides adding and removing NPCs while the world runs.
+package world
+
+import (
+	"fmt"
+	"gobotworld/src/geometry"
+	"gobotworld/src/world/object"
+	"image"
+	"slices"
+)
+
+// SpawnRule keeps the number of NPCs of a type near a target that changes with
+// the time of day. Every Every ticks one NPC is spawned if there are too few,
+// or the one furthest from the player despawned if there are too many.
+type SpawnRule struct {
+	Type  object.ObjectType `json:"type"`
+	Day   int               `json:"day"`
+	Night int               `json:"night"`
+	Every int               `json:"every"`
+	// MinDistance keeps NPCs from appearing or vanishing next to the player.
+	MinDistance int `json:"minDistance"`
+}
+
+// DefaultSpawnDistance is how far from the player NPCs spawn and despawn when
+// a rule doesn't say.
+const DefaultSpawnDistance = 10
+
+// spawnAttempts is how many random cells are tried before giving up on
+// spawning an NPC until the next check.
+const spawnAttempts = 50
+
+func (rule SpawnRule) target(cycle object.DayCycle) int {
+	if cycle == object.NightTime {
+		return rule.Night
+	}
+	return rule.Day
+}
+
+// Spawn creates a being of type t at p with an index of its own. It fails if
+// p is off the map or something is in the way.
+func (world World) Spawn(t object.ObjectType, p image.Point) (*object.Character, error) {
+	if !world.Geography.CanPass(p, nil) {
+		return nil, fmt.Errorf("cannot spawn a %s at %v", t, p)
+	}
+	being := object.NewCharacter(world.ids.Next(), t, p)
+	world.Geography.AddLoc(p, being)
+	world.Beings[being] = false
+	world.behaviours[being] = NewBehaviour(being)
+	return being, nil
+}
+
+// Despawn takes a being off the map along with its controller and memory.
+// Actions still queued for it are dropped.
+func (world World) Despawn(being *object.Character) bool {
+	if _, ok := world.Beings[being]; !ok || being == world.Player {
+		return false
+	}
+	world.Geography.RemoveLoc(*being.Location, being)
+	delete(world.Beings, being)
+	delete(world.behaviours, being)
+	delete(world.controllers, being)
+	delete(world.memories, being)
+	delete(world.lanterns, being)
+	return true
+}
+
+// populate applies the spawn rules that are due this tick.
+func (world World) populate() {
+	cycle, _ := world.Cycle()
+	for _, rule := range world.spawning {
+		if rule.Every <= 0 || *world.Time%rule.Every != 0 {
+			continue
+		}
+
+		var current []*object.Character
+		for _, being := range world.sortedBeings() {
+			if being.Ident().Type == rule.Type && !world.Beings[being] {
+				current = append(current, being)
+			}
+		}
+
+		switch target := rule.target(cycle); {
+		case len(current) < target:
+			if p, ok := world.spawnPoint(rule.MinDistance); ok {
+				being, _ := world.Spawn(rule.Type, p)
+				world.logger.Printf("Spawned %s %d at %v", rule.Type, being.Ident().Index, p)
+			}
+		case len(current) > target:
+			furthest := slices.MaxFunc(current, func(a, b *object.Character) int {
+				return world.distanceToPlayer(*a.Location) - world.distanceToPlayer(*b.Location)
+			})
+			if world.distanceToPlayer(*furthest.Location) >= rule.MinDistance {
+				world.Despawn(furthest)
+				world.logger.Printf("Despawned %s %d", rule.Type, furthest.Ident().Index)
+			}
+		}
+	}
+}
+
+func (world World) distanceToPlayer(p image.Point) int {
+	return geometry.Distance(*world.Player.Location, p)
+}
+
+// spawnPoint picks a random free cell at least distance from the player.
+func (world World) spawnPoint(distance int) (image.Point, bool) {
+	area := world.Geography.Bounds()
+	if chunks, ok := world.Geography.(*ChunkedMap); ok {
+		area = chunks.loadedArea()
+	}
+	if area.Empty() {
+		return image.Point{}, false
+	}
+	for range spawnAttempts {
+		p := image.Point{
+			X: area.Min.X + world.rnd.Intn(area.Dx()),
+			Y: area.Min.Y + world.rnd.Intn(area.Dy()),
+		}
+		if world.Geography.CanPass(p, nil) && world.distanceToPlayer(p) >= distance {
+			return p, true
+		}
+	}
+	return image.Point{}, false
+}
@@ -0,0 +1,123 @@
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"image"
+	"io"
+	"log"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func countNPCs(w World, t object.ObjectType) int {
+	count := 0
+	for being := range w.Beings {
+		if being.Ident().Type == t {
+			count++
+		}
+	}
+	return count
+}
+
+func TestBeingsHaveTheirOwnIndex(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	w := InitWorld(logger, 40, 40, EmptyConfig().WithSeed(1).WithNPCs(
+		NPCSpawn{Type: object.EnemyType},
+		NPCSpawn{Type: object.EnemyType},
+		NPCSpawn{Type: object.CritterType},
+		NPCSpawn{Type: object.CritterType, Location: &image.Point{X: 5, Y: 5}},
+	))
+
+	seen := map[int]bool{}
+	for being := range w.Beings {
+		assert.False(t, seen[being.Ident().Index], "Index %d is used twice", being.Ident().Index)
+		seen[being.Ident().Index] = true
+	}
+	assert.Len(t, seen, 5)
+	assert.Equal(t, 2, countNPCs(w, object.EnemyType))
+	assert.Equal(t, 2, countNPCs(w, object.CritterType), "NPCs should be the kind asked for")
+}
+
+func TestNPCsDontWalkThroughEachOther(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	w := InitWorld(logger, 10, 10, EmptyConfig().WithSeed(1).WithNPCs(
+		NPCSpawn{Type: object.EnemyType, Location: &image.Point{X: 2, Y: 2}},
+		NPCSpawn{Type: object.EnemyType, Location: &image.Point{X: 3, Y: 2}},
+	))
+
+	var west *object.Character
+	for being := range w.Beings {
+		if *being.Location == (image.Point{X: 2, Y: 2}) {
+			west = being
+		}
+	}
+	require.NotNil(t, west)
+	assert.False(t, w.Move(west, object.East), "An NPC should not step onto another")
+}
+
+func TestSpawnAndDespawn(t *testing.T) {
+	w := EmptyWorld(log.New(io.Discard, "", log.LstdFlags))
+	p := w.Player.Location.Add(image.Point{X: 3})
+	w.Geography.SetLoc(p, object.ThingList{object.NewObject(0, object.Dirt1Type, true)})
+
+	being, err := w.Spawn(object.CritterType, p)
+	require.NoError(t, err)
+	assert.Contains(t, w.Beings, being)
+	assert.Contains(t, w.Geography.At(p), object.Thing(being))
+	assert.Greater(t, being.Ident().Index, w.Player.Ident().Index, "Spawned beings get new indexes")
+
+	_, err = w.Spawn(object.EnemyType, p)
+	assert.Error(t, err, "Nothing can spawn where a being already stands")
+
+	w.Enqueue(MoveAction(being, object.North))
+	assert.True(t, w.Despawn(being))
+	assert.NotContains(t, w.Beings, being)
+	assert.NotContains(t, w.Geography.At(p), object.Thing(being), "Despawned beings leave the map")
+	w.Tick()
+	assert.NotContains(t, w.Geography.At(p.Add(image.Point{Y: -1})), object.Thing(being), "Queued actions of despawned beings are dropped")
+
+	assert.False(t, w.Despawn(w.Player), "The player can't be despawned")
+}
+
+func TestSpawnRulesFollowTheTimeOfDay(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	rule := SpawnRule{Type: object.EnemyType, Day: 1, Night: 4, Every: 1}
+	w := InitWorld(logger, 60, 60, EmptyConfig().WithSeed(2).WithNPCs().WithSpawnRules(rule))
+
+	for range 40 {
+		w.Tick()
+	}
+	cycle, _ := w.Cycle()
+	require.Equal(t, object.DayTime, cycle)
+	assert.Equal(t, 1, countNPCs(w, object.EnemyType), "Day should have one enemy")
+
+	for range 38 {
+		w.Tick()
+	}
+	cycle, _ = w.Cycle()
+	require.Equal(t, object.NightTime, cycle)
+	assert.Equal(t, 4, countNPCs(w, object.EnemyType), "More enemies should come out at night")
+
+	for range 10 {
+		w.Tick()
+	}
+	assert.Equal(t, 1, countNPCs(w, object.EnemyType), "Enemies should go again in the morning")
+}
+
+func TestSpawnRulesKeepTheirDistance(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	rule := SpawnRule{Type: object.CritterType, Day: 5, Every: 1, MinDistance: 15}
+	w := InitWorld(logger, 60, 60, EmptyConfig().WithSeed(3).WithNPCs().WithSpawnRules(rule))
+
+	for range 10 {
+		w.Tick()
+	}
+	require.Equal(t, 5, countNPCs(w, object.CritterType))
+	for being := range w.Beings {
+		if being != w.Player {
+			assert.GreaterOrEqual(t, w.distanceToPlayer(*being.Location), 15)
+		}
+	}
+}
@@ -0,0 +1,162 @@
+// Package provides a compact map for worlds too large for Map.
+package world
+
+import (
+	"gobotworld/src/world/object"
+	"image"
+	"iter"
+	"slices"
+)
+
+// TileID indexes the palette of a TileMap.
+type TileID uint8
+
+// overlaid marks a cell whose things are in the overlay, which leaves 255
+// tiles for terrain. Any more terrain things are overlaid like everything else.
+const (
+	overlaid = TileID(255)
+	maxTiles = int(overlaid)
+)
+
+// TileMap is a Grid that stores each plain terrain cell as a one byte tile in
+// a flat array. Cells holding anything else, such as a character standing on
+// the terrain or a cell emptied with SetLoc, are kept whole in a sparse
+// overlay. A 2000x2000 TileMap takes about 4MB where a Map takes over 150MB.
+type TileMap struct {
+	width, height int
+	tiles         []TileID
+	// palette is indexed by every possible TileID so that reading it needs
+	// no bounds check, the overlaid entry is never used.
+	palette [maxTiles + 1]object.ThingList
+	ids     map[object.BasicObject]TileID
+	overlay map[int]object.ThingList
+}
+
+// NewTileMap creates a map where every cell is fill.
+func NewTileMap(width, height int, fill object.BasicObject) *TileMap {
+	tm := newTileMap(width, height)
+	tm.tile(fill)
+	return tm
+}
+
+// newTileMap creates a map with an empty palette, every cell must be set
+// before it is read.
+func newTileMap(width, height int) *TileMap {
+	return &TileMap{
+		width:   width,
+		height:  height,
+		tiles:   make([]TileID, width*height),
+		ids:     map[object.BasicObject]TileID{},
+		overlay: map[int]object.ThingList{},
+	}
+}
+
+// CompactMap copies a Map into a TileMap.
+func CompactMap(m Map) *TileMap {
+	tm := newTileMap(m.Width(), m.Height())
+	for p, things := range m.Cells() {
+		tm.SetLoc(p, things)
+	}
+	return tm
+}
+
+// tile returns the tile for a terrain thing, adding it to the palette if it
+// is new and there is room.
+func (tm *TileMap) tile(thing object.BasicObject) (TileID, bool) {
+	if id, ok := tm.ids[thing]; ok {
+		return id, true
+	}
+	if len(tm.ids) == maxTiles {
+		return 0, false
+	}
+	id := TileID(len(tm.ids))
+	tm.ids[thing] = id
+	tm.palette[id] = slices.Clip(object.ThingList{thing})
+	return id, true
+}
+
+func (tm *TileMap) index(p image.Point) (int, bool) {
+	if p.X < 0 || p.Y < 0 || p.X >= tm.width || p.Y >= tm.height {
+		return 0, false
+	}
+	return p.Y*tm.width + p.X, true
+}
+
+// At returns the things at p. Plain terrain cells share their list with every
+// other cell of the same tile, so the list must not be changed in place. Each
+// palette list holds exactly one thing with no room to grow, so appending to
+// one copies it.
+func (tm *TileMap) At(p image.Point) object.ThingList {
+	// Comparing as unsigned catches negative coordinates too
+	if uint(p.X) >= uint(tm.width) || uint(p.Y) >= uint(tm.height) {
+		return nil
+	}
+	i := p.Y*tm.width + p.X
+	if id := tm.tiles[i]; id != overlaid {
+		return tm.palette[id]
+	}
+	return tm.overlay[i]
+}
+
+func (tm *TileMap) SetLoc(p image.Point, things object.ThingList) {
+	i, ok := tm.index(p)
+	if !ok {
+		return
+	}
+	if len(things) == 1 {
+		if thing, ok := things[0].(object.BasicObject); ok {
+			if id, ok := tm.tile(thing); ok {
+				tm.tiles[i] = id
+				delete(tm.overlay, i)
+				return
+			}
+		}
+	}
+	tm.tiles[i] = overlaid
+	tm.overlay[i] = things
+}
+
+func (tm *TileMap) AddLoc(p image.Point, thing object.Thing) {
+	tm.SetLoc(p, append(slices.Clone(tm.At(p)), thing))
+}
+
+// RemoveLoc takes thing off p. Once only the terrain is left the cell goes
+// back to being a tile.
+func (tm *TileMap) RemoveLoc(p image.Point, thing object.Thing) {
+	tm.SetLoc(p, slices.Clone(tm.At(p)).DeleteItem(thing))
+}
+
+func (tm *TileMap) CanPass(p image.Point, thing object.Thing) bool {
+	if _, ok := tm.index(p); !ok {
+		return false
+	}
+	for _, obj := range tm.At(p) {
+		if !obj.Passable(thing) {
+			return false
+		}
+	}
+	return true
+}
+
+func (tm *TileMap) Bounds() image.Rectangle {
+	return image.Rect(0, 0, tm.width, tm.height)
+}
+
+// Cells visits the map row by row.
+func (tm *TileMap) Cells() iter.Seq2[image.Point, object.ThingList] {
+	return func(yield func(image.Point, object.ThingList) bool) {
+		for y := range tm.height {
+			for x := range tm.width {
+				p := image.Point{X: x, Y: y}
+				if !yield(p, tm.At(p)) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Overlaid returns how many cells are held in the overlay rather than as tiles.
+func (tm *TileMap) Overlaid() int {
+	return len(tm.overlay)
+}
@@ -0,0 +1,186 @@
+package world
+
+import (
+	"bytes"
+	"gobotworld/src/world/object"
+	"image"
+	"io"
+	"log"
+	"runtime"
+	"runtime/metrics"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTileMapMatchesMap(t *testing.T) {
+	m, lights := RandomMap(40, 50, DefaultConfig().WithSeed(6))
+	tm := CompactMap(m)
+
+	assert.Equal(t, m.Bounds(), tm.Bounds())
+	for p, things := range m.Cells() {
+		assert.Equal(t, things, tm.At(p), "Cell %v should be the same", p)
+		assert.Equal(t, m.CanPass(p, nil), tm.CanPass(p, nil), "Cell %v should be as passable", p)
+	}
+	assert.Equal(t, len(lights), tm.Overlaid(), "Plain terrain should all be tiles, only lights are overlaid")
+	assert.Nil(t, tm.At(image.Point{X: -1, Y: 0}))
+	assert.False(t, tm.CanPass(image.Point{X: 50, Y: 0}, nil))
+}
+
+func TestRandomTileMap(t *testing.T) {
+	m, lights := RandomMap(40, 50, DefaultConfig().WithSeed(6))
+	tm, tileLights := RandomTileMap(40, 50, DefaultConfig().WithSeed(6))
+
+	assert.Equal(t, m.Bounds(), tm.Bounds())
+	for p, things := range m.Cells() {
+		assert.Equal(t, things, tm.At(p), "Cell %v should be the same", p)
+	}
+	assert.Equal(t, lights, tileLights)
+
+	grid, _ := generate(Weighted, 40, 50, DefaultConfig().WithSeed(6).WithCompactTiles())
+	assert.IsType(t, &TileMap{}, grid, "Compact maps should be generated as tiles")
+}
+
+func TestTileMapOverlay(t *testing.T) {
+	dirt := object.NewObject(0, object.Dirt1Type, true)
+	tm := NewTileMap(5, 5, dirt)
+	p := image.Point{X: 2, Y: 3}
+	npc := object.NewNPC(p)
+
+	tm.AddLoc(p, npc)
+	assert.Equal(t, object.ThingList{dirt, npc}, tm.At(p))
+	assert.Equal(t, 1, tm.Overlaid())
+	assert.Equal(t, object.ThingList{dirt}, tm.At(image.Point{X: 1, Y: 1}), "Other cells of the tile should be untouched")
+
+	tm.RemoveLoc(p, npc)
+	assert.Equal(t, object.ThingList{dirt}, tm.At(p))
+	assert.Zero(t, tm.Overlaid(), "A cell back to plain terrain should be a tile again")
+
+	tm.SetLoc(p, object.ThingList{object.NewObject(0, object.ObstacleType, false)})
+	assert.False(t, tm.CanPass(p, nil), "New terrain should become a new tile")
+	assert.Zero(t, tm.Overlaid())
+
+	tm.SetLoc(p, nil)
+	assert.Nil(t, tm.At(p), "Empty cells are kept as they are")
+	assert.Equal(t, 1, tm.Overlaid())
+}
+
+func TestCompactWorldMatchesWorld(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	plain := InitWorld(logger, 60, 60, DefaultConfig().WithSeed(8))
+	compact := InitWorld(logger, 60, 60, DefaultConfig().WithSeed(8).WithCompactTiles())
+	require.IsType(t, &TileMap{}, compact.Geography)
+
+	for range 30 {
+		plain.Enqueue(MoveAction(plain.Player, object.West))
+		compact.Enqueue(MoveAction(compact.Player, object.West))
+		plain.Tick()
+		compact.Tick()
+		plain.NpcMove()
+		compact.NpcMove()
+	}
+	assert.Equal(t, plain.Hash(), compact.Hash(), "How the map is stored should not change the simulation")
+
+	var buf bytes.Buffer
+	require.NoError(t, compact.Save(&buf))
+	restored, err := Load(logger, &buf)
+	require.NoError(t, err)
+	assert.IsType(t, &TileMap{}, restored.Geography, "Compact worlds should load compact")
+	assert.Equal(t, compact.Hash(), restored.Hash())
+}
+
+const benchSize = 2000
+
+// reportMemory reports how much of the heap is still in use by what build
+// returns, once it has been built and everything else collected, and the most
+// the heap grew by while it was being built.
+func reportMemory(b *testing.B, build func() Grid) {
+	sample := []metrics.Sample{{Name: "/memory/classes/heap/objects:bytes"}}
+	heap := func() uint64 {
+		metrics.Read(sample)
+		return sample[0].Value.Uint64()
+	}
+	var live, peak uint64
+	for range b.N {
+		runtime.GC()
+		before := heap()
+		done, highest := make(chan bool), make(chan uint64)
+		go func() {
+			high := before
+			ticker := time.NewTicker(100 * time.Microsecond)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-done:
+					highest <- max(high, heap())
+					return
+				case <-ticker.C:
+					high = max(high, heap())
+				}
+			}
+		}()
+		grid := build()
+		done <- true
+		peak = <-highest - before
+		runtime.GC()
+		live = heap() - before
+		runtime.KeepAlive(grid)
+	}
+	b.ReportMetric(float64(live), "live-B")
+	b.ReportMetric(float64(peak), "peak-B")
+}
+
+func BenchmarkMapMemory(b *testing.B) {
+	reportMemory(b, func() Grid {
+		m, _ := RandomMap(benchSize, benchSize, DefaultConfig().WithSeed(1))
+		return m
+	})
+}
+
+// BenchmarkCompactMapMemory is how generators that can only build a Map make
+// a compact one.
+func BenchmarkCompactMapMemory(b *testing.B) {
+	reportMemory(b, func() Grid {
+		m, _ := RandomMap(benchSize, benchSize, DefaultConfig().WithSeed(1))
+		return CompactMap(m)
+	})
+}
+
+func BenchmarkTileMapMemory(b *testing.B) {
+	reportMemory(b, func() Grid {
+		tm, _ := RandomTileMap(benchSize, benchSize, DefaultConfig().WithSeed(1))
+		return tm
+	})
+}
+
+// scan reads a screen sized window of cells the way the renderer does. The
+// window moves each time, as it does when the player walks about a large map.
+func scan(g Grid, n int) int {
+	origin := image.Point{X: n * 37 % (benchSize - 200), Y: n * 53 % (benchSize - 60)}
+	count := 0
+	for y := origin.Y; y < origin.Y+60; y++ {
+		for x := origin.X; x < origin.X+200; x++ {
+			count += len(g.At(image.Point{X: x, Y: y}))
+		}
+	}
+	return count
+}
+
+func BenchmarkScanMap(b *testing.B) {
+	m, _ := RandomMap(benchSize, benchSize, DefaultConfig().WithSeed(1))
+	b.ResetTimer()
+	for n := range b.N {
+		scan(m, n)
+	}
+}
+
+func BenchmarkScanTileMap(b *testing.B) {
+	m, _ := RandomMap(benchSize, benchSize, DefaultConfig().WithSeed(1))
+	tm := CompactMap(m)
+	b.ResetTimer()
+	for n := range b.N {
+		scan(tm, n)
+	}
+}
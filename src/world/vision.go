@@ -2,35 +2,21 @@
 package world
 
 import (
-	"gobotworld/src/geometry"
 	"gobotworld/src/world/object"
 	"image"
 )
 
-// Vision calculates the light intensity at a specific point based on
-// surrounding light sources and the current day/night cycle.
+// Vision returns the light at a specific point, read from the light map of the
+// last tick, and the current day/night cycle.
 //
 // Parameters:
 //   - pt: The target point for light calculation.
-//   - viewable: The visible region as a geometry.Window.
-//   - world: The world instance containing light sources and time information.
+//   - world: The world instance containing the light map and time information.
 //
 // Returns:
-//   - An object.LightBlock containing the calculated light intensity (Lumen)
-//     and the time of day.
-func Vision(pt image.Point, viewable geometry.Window, world World) object.LightBlock {
-	lumen := 0
-	for _, light := range world.Lights {
-		lightWnd := geometry.Circle(light, object.TorchArea)
-		if viewable.Overlap(lightWnd) {
-			result := object.LightAt(pt, *light, object.TorchArea)
-			if result > lumen {
-				lumen = result
-			}
-		}
-	}
-
-	cycle, _ := object.Time(*world.Time)
-
-	return object.LightBlock{Time: cycle, Lumen: lumen}
+//   - An object.LightBlock containing the light intensity (Lumen) and the
+//     time of day, and the colour of the light.
+func Vision(pt image.Point, world World) object.LightBlock {
+	cycle, _ := world.Cycle()
+	return object.LightBlock{Time: cycle, Lumen: world.lighting.Lumen(pt), Colour: world.lighting.Colour(pt)}
 }
@@ -7,6 +7,7 @@ import (
 	"iter"
 	"log"
 	"math/rand"
+	"slices"
 )
 
 const (
@@ -50,6 +51,27 @@ func (m Map) Width() int {
 	return len(m[0])
 }
 
+// Bounds is the rectangle from (0, 0) to the width and height of the map.
+func (m Map) Bounds() image.Rectangle {
+	if len(m) == 0 {
+		return image.Rectangle{}
+	}
+	return image.Rect(0, 0, m.Width(), m.Height())
+}
+
+// Cells visits the map row by row.
+func (m Map) Cells() iter.Seq2[image.Point, object.ThingList] {
+	return func(yield func(image.Point, object.ThingList) bool) {
+		for y, row := range m {
+			for x, things := range row {
+				if !yield(image.Point{X: x, Y: y}, things) {
+					return
+				}
+			}
+		}
+	}
+}
+
 func (m Map) CanPass(point image.Point, thing object.Thing) bool {
 	if point.Y < 0 || point.X < 0 || point.Y >= len(m) || point.X >= len(m[0]) {
 		return false
@@ -66,100 +88,294 @@ func (m Map) CanPass(point image.Point, thing object.Thing) bool {
 
 func RandomMap(height, width int, cfg Config) (Map, object.Lights) {
 	geography := make(Map, height)
-	var lights object.Lights
 	for i := range geography {
-		geography[i] = make([]object.ThingList, 0, width)
-		for j := 0; j < width; j++ {
+		geography[i] = make([]object.ThingList, width)
+	}
+	return geography, randomTerrain(geography, cfg)
+}
+
+// RandomTileMap is RandomMap built straight into a TileMap. The same config
+// and seed give the same map.
+func RandomTileMap(height, width int, cfg Config) (*TileMap, object.Lights) {
+	tm := newTileMap(width, height)
+	return tm, randomTerrain(tm, cfg)
+}
+
+// randomTerrain fills every cell of g with terrain drawn from the config, row
+// by row, and returns the lights among it.
+func randomTerrain(g Grid, cfg Config) object.Lights {
+	var lights object.Lights
+	bounds := g.Bounds()
+	for i := range bounds.Dy() {
+		for j := range bounds.Dx() {
 			rndObj := cfg.RandomObject()
 			if rndObj.Ident().Type == object.TorchType {
-				lights = append(lights, &image.Point{X: j, Y: i})
+				light := cfg.newTorch(image.Point{X: j, Y: i})
+				lights = append(lights, light)
+				rndObj = light
 			}
-			geography[i] = append(geography[i], object.ThingList{rndObj})
+			g.SetLoc(image.Point{X: j, Y: i}, object.ThingList{rndObj})
 		}
 	}
-	return geography, lights
+	return lights
 }
 
 // World represents the entire simulated world, including the map, player, NPCs, lights, and time.
 type World struct {
-	logger    *log.Logger
-	Geography Map
-	Player    *object.Character
-	Beings    map[*object.Character]bool
-	Lights    object.Lights
-	Time      *int // TODO: Make private
+	logger      *log.Logger
+	Geography   Grid
+	Player      *object.Character
+	Beings      map[*object.Character]bool // true for the player
+	Lights      *object.Lights
+	Time        *int // TODO: Make private
+	seed        int64
+	src         *countingSource
+	rnd         *rand.Rand
+	actions     *actionQueue
+	ids         *object.IDs
+	spawning    []SpawnRule
+	behaviours  map[*object.Character]*Behaviour
+	controllers map[*object.Character]Controller
+	memories    map[*object.Character]*memory
+	lanterns    map[*object.Character]*object.Light
+	lighting    *LightMap
+	dayLength   int
+	torchRadius int
 }
 
-func EmptyWorld(logger *log.Logger) World {
-	cfg := NewConfig(
+// EmptyConfig is the terrain used by EmptyWorld, it has no obstacles or lights.
+func EmptyConfig() Config {
+	return NewConfig(
 		DefaultTerrain{object.NewObject(0, object.Dirt1Type, true), 400},
 		DefaultTerrain{object.NewObject(0, object.Dirt2Type, true), 250},
 		DefaultTerrain{object.NewObject(0, object.RockType, true), 50},
 	)
-	return InitWorld(logger, Height, Width, cfg)
 }
 
-func DefaultWorld(logger *log.Logger) World {
-	cfg := NewConfig(
+// DefaultConfig is the terrain used by DefaultWorld.
+func DefaultConfig() Config {
+	return NewConfig(
 		DefaultTerrain{object.NewObject(0, object.Dirt1Type, true), 400},
 		DefaultTerrain{object.NewObject(0, object.Dirt2Type, true), 250},
 		DefaultTerrain{object.NewObject(0, object.RockType, true), 50},
 		DefaultTerrain{object.NewObject(0, object.ObstacleType, false), 5},
 		DefaultTerrain{object.NewObject(0, object.TorchType, false), 1},
 	)
+}
 
-	return InitWorld(logger, Height, Width, cfg)
+func EmptyWorld(logger *log.Logger) World {
+	return InitWorld(logger, Height, Width, EmptyConfig())
+}
+
+func DefaultWorld(logger *log.Logger) World {
+	return InitWorld(logger, Height, Width, DefaultConfig())
+}
+
+// SeededWorld creates a default world where the same seed always produces the
+// same map and, given the same inputs, the same NPC movement.
+func SeededWorld(logger *log.Logger, seed int64) World {
+	return InitWorld(logger, Height, Width, DefaultConfig().WithSeed(seed))
 }
 
 func InitWorld(logger *log.Logger, height, width int, cfg Config) World {
-	geography, lights := RandomMap(height, width, cfg)
+	generator := cfg.generator
+	if generator == nil {
+		generator = Weighted
+	}
+	grid, lights := generate(generator, height, width, cfg)
 
-	playerLocation := image.Point{X: width / 2, Y: height / 2}
-	enemyLocation := image.Point{X: 10, Y: 10}
-	start := 0
-	player := object.NewPlayer(playerLocation)
-	enemy := object.NewNPC(enemyLocation)
+	regions := FindRegions(grid)
+	if cfg.connectLights {
+		regions = ConnectLights(grid, lights, object.NewObject(0, object.Dirt1Type, true))
+	}
+	spawns := newSpawner(regions, cfg.rnd)
+
+	ids := object.NewIDs(1)
+	centre := image.Point{X: width / 2, Y: height / 2}
+	player, beings, behaviours := placeBeings(grid, spawns, ids, image.Point{}, centre, cfg)
+
+	logger.Print("Working with a map of size ", grid.Bounds().Dy(), "x", grid.Bounds().Dx())
+	return newWorld(logger, grid, lights, player, beings, behaviours, ids, cfg)
+}
 
+// InitChunkedWorld creates a world on a map that is generated as it is
+// explored. The chunks around the player spawn, or (0, 0) if the config
+// doesn't give one, are generated first and every being is placed in the
+// largest region among them.
+func InitChunkedWorld(logger *log.Logger, chunks *ChunkedMap, cfg Config) World {
+	centre := image.Point{}
+	if cfg.playerSpawn != nil {
+		centre = *cfg.playerSpawn
+	}
+	chunks.Stream([]image.Point{centre})
+
+	area := chunks.loadedArea()
+	spawns := newSpawner(FindRegions(chunks.view(area)), cfg.rnd)
+	ids := object.NewIDs(1)
+	player, beings, behaviours := placeBeings(chunks, spawns, ids, area.Min, centre, cfg)
+
+	logger.Print("Working with an unbounded map of ", len(chunks.Loaded()), " chunks")
+	world := newWorld(logger, chunks, nil, player, beings, behaviours, ids, cfg)
+	world.stream()
+	return world
+}
+
+// placeBeings puts the player and the NPCs of the config on the map, each with
+// an index from ids, and gives the NPCs their behaviour. The spawner works on a
+// view of the map whose top left is at origin.
+func placeBeings(geography Grid, spawns *spawner, ids *object.IDs, origin, centre image.Point, cfg Config) (*object.Character, map[*object.Character]bool, map[*object.Character]*Behaviour) {
+	playerLocation := centre
+	if cfg.playerSpawn != nil {
+		playerLocation = *cfg.playerSpawn
+	}
+	playerLocation = spawns.near(playerLocation.Sub(origin)).Add(origin)
+	player := object.NewCharacter(ids.Next(), object.PlayerType, playerLocation)
 	geography.AddLoc(playerLocation, player)
-	geography.AddLoc(enemyLocation, enemy)
+	beings := map[*object.Character]bool{player: true}
+	behaviours := map[*object.Character]*Behaviour{}
+
+	for _, spawn := range cfg.npcs {
+		var location image.Point
+		if spawn.Location != nil {
+			location = spawns.near(spawn.Location.Sub(origin))
+		} else {
+			location = spawns.random()
+		}
+		location = location.Add(origin)
+		npc := object.NewCharacter(ids.Next(), spawn.Type, location)
+		geography.AddLoc(location, npc)
+		beings[npc] = false
+		behaviours[npc] = NewBehaviour(npc, spawn.Route...)
+	}
+	return player, beings, behaviours
+}
+
+func newWorld(logger *log.Logger, geography Grid, lights object.Lights, player *object.Character, beings map[*object.Character]bool, behaviours map[*object.Character]*Behaviour, ids *object.IDs, cfg Config) World {
+	start := 0
+	world := World{
+		logger:      logger,
+		Geography:   geography,
+		Lights:      &lights,
+		Player:      player,
+		Beings:      beings,
+		dayLength:   cfg.dayLength,
+		torchRadius: cfg.torchLight().Radius,
+		Time:        &start,
+		seed:        cfg.seed,
+		src:         cfg.src,
+		rnd:         cfg.rnd,
+		actions:     &actionQueue{},
+		ids:         ids,
+		spawning:    cfg.spawning,
+		behaviours:  behaviours,
+		controllers: map[*object.Character]Controller{},
+		memories:    map[*object.Character]*memory{},
+		lanterns:    map[*object.Character]*object.Light{},
+		lighting:    newLightMap(),
+	}
+	if cfg.lantern > 0 {
+		world.Carry(player, object.NewLantern(cfg.lantern))
+	}
+	world.lighting.update(world)
+	return world
+}
+
+// Cycle returns whether it is day or night and how far through it we are.
+func (world World) Cycle() (object.DayCycle, int) {
+	return object.TimeOfDay(*world.Time, world.dayLength)
+}
+
+// TorchRadius is how far the light of each torch reaches.
+func (world World) TorchRadius() int {
+	return world.torchRadius
+}
 
-	logger.Print("Working with a map of size ", geography.Height(), "x", geography.Width())
-	return World{
-		logger:    logger,
-		Geography: geography,
-		Lights:    lights,
-		Player:    player,
-		Beings: map[*object.Character]bool{
-			player: true,
-			enemy:  false,
-		},
-		Time: &start,
+// LightSources returns every light in the world, those on the map, those the
+// map has generated since it was created and those the beings carry.
+func (world World) LightSources() object.Lights {
+	lights := slices.Clip(*world.Lights)
+	if source, ok := world.Geography.(lightSource); ok {
+		lights = append(lights, source.Lights()...)
 	}
+	return append(lights, world.carried()...)
 }
 
+// Seed returns the seed the world was generated from.
+func (world World) Seed() int64 {
+	return world.seed
+}
+
+// Tick asks the controllers what their beings do, applies every queued
+// action in the order they were queued, lights the world, lets beings
+// remember what they can see and then advances the clock.
 func (world World) Tick() {
+	world.control()
+	actions, observers := world.actions.drain()
+	for _, action := range actions {
+		for _, observer := range observers {
+			observer(*world.Time, action)
+		}
+		if !world.apply(action) {
+			world.logger.Printf("Being %d could not %s %s", action.Being.Ident().Index, action.Kind, action.Direction)
+		}
+	}
+	world.populate()
+	world.stream()
+	world.lighting.update(world)
+	world.remember()
 	*world.Time += 1
 }
 
+// stream lets a map that generates itself as it is explored catch up with
+// where the beings have got to.
+func (world World) stream() {
+	grid, ok := world.Geography.(streamer)
+	if !ok {
+		return
+	}
+	focus := make([]image.Point, 0, len(world.Beings))
+	for _, being := range world.sortedBeings() {
+		focus = append(focus, *being.Location)
+	}
+	for _, area := range grid.Stream(focus) {
+		world.lighting.relight(area)
+	}
+}
+
 var directions = []object.Direction{object.North, object.South, object.East, object.West}
 
+// sortedBeings returns the beings in a stable order so that anything drawing
+// from the world's random source does so in the same sequence every run.
+func (world World) sortedBeings() []*object.Character {
+	beings := make([]*object.Character, 0, len(world.Beings))
+	for being := range world.Beings {
+		beings = append(beings, being)
+	}
+	slices.SortFunc(beings, func(a, b *object.Character) int {
+		if a.Ident().Index != b.Ident().Index {
+			return a.Ident().Index - b.Ident().Index
+		}
+		if a.Location.Y != b.Location.Y {
+			return a.Location.Y - b.Location.Y
+		}
+		return a.Location.X - b.Location.X
+	})
+	return beings
+}
+
+// NpcMove moves every NPC without a controller as its behaviour decides.
 func (world World) NpcMove() {
-	for being, isPlayer := range world.Beings {
-		if isPlayer {
+	for _, being := range world.sortedBeings() {
+		if _, controlled := world.controllers[being]; controlled || world.Beings[being] {
 			continue
 		}
-
-		// Shuffle the directions
-		rand.Shuffle(len(directions), func(i, j int) { directions[i], directions[j] = directions[j], directions[i] })
-
-		// Try to move in each direction
-		for _, direction := range directions {
-			if moved := world.Move(being, direction); moved {
-				// If the move was successful, stop trying to move
-				world.logger.Printf("NPC %d moved %s", being.Ident().Index, direction.String())
-				break
-			}
+		b, ok := world.behaviours[being]
+		if !ok {
+			b = NewBehaviour(being)
+			world.behaviours[being] = b
 		}
+		world.think(being, b)
+		world.act(being, b)
 	}
 }
 
@@ -183,7 +399,7 @@ func (world World) Neighbours(p image.Point) iter.Seq[image.Point] {
 			q := p.Add(off)
 
 			// Check map boundaries
-			if q.X < 0 || q.X >= world.Geography.Width() || q.Y < 0 || q.Y >= world.Geography.Height() {
+			if !q.In(world.Geography.Bounds()) {
 				continue
 			}
 
@@ -215,7 +431,7 @@ func (world World) Move(char *object.Character, direction object.Direction) bool
 	}
 
 	for being := range world.Beings {
-		if char.Ident().Index == being.Ident().Index { // Ignore if we are the same being
+		if char == being { // Ignore if we are the same being
 			continue
 		}
 		if being.Location.X == proposed.X && being.Location.Y == proposed.Y && !being.Passable(char) {
@@ -224,12 +440,18 @@ func (world World) Move(char *object.Character, direction object.Direction) bool
 	}
 
 	if world.Geography.CanPass(proposed, char) {
-		world.Geography.RemoveLoc(*location, char)
-		world.Geography.AddLoc(proposed, char)
-		char.Location = &proposed
+		world.Relocate(char, proposed)
 
 		return true
 	}
 
 	return false
 }
+
+// Relocate puts a being down at p whatever is in the way. Beings in play Move,
+// this is for tests and tools setting a world up.
+func (world World) Relocate(being *object.Character, p image.Point) {
+	world.Geography.RemoveLoc(*being.Location, being)
+	world.Geography.AddLoc(p, being)
+	being.Location = &p
+}
@@ -3,6 +3,7 @@ package world
 import (
 	"gobotworld/src/world/object"
 	"image"
+	"io"
 	"log"
 	"os"
 	"testing"
@@ -30,7 +31,7 @@ func TestWorldInitialization(t *testing.T) {
 
 	assert.NotNil(t, worldInstance.Player, "Player should not be nil")
 	assert.NotNil(t, worldInstance.Geography, "Geography should not be nil")
-	assert.Greater(t, len(worldInstance.Lights), 0, "Lights should be initialized")
+	assert.Greater(t, len(*worldInstance.Lights), 0, "Lights should be initialized")
 	assert.Contains(t, worldInstance.Beings, worldInstance.Player, "Player should be in the list of beings")
 }
 
@@ -116,3 +117,48 @@ func TestTimeCycle(t *testing.T) {
 	assert.Equal(t, 1, cnt, "Cnt is how far into the day we are")
 	assert.Equal(t, object.NightTime, cycle, "Time cycle should switch to NightTime after enough ticks")
 }
+
+func TestSeededWorldIsDeterministic(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	first := SeededWorld(logger, 42)
+	second := SeededWorld(logger, 42)
+
+	assert.Equal(t, first.Geography, second.Geography, "Same seed should produce the same map")
+	assert.Equal(t, first.Lights, second.Lights, "Same seed should produce the same lights")
+	assert.Equal(t, int64(42), first.Seed(), "World should remember its seed")
+
+	for i := 0; i < 50; i++ {
+		first.Tick()
+		first.NpcMove()
+		second.Tick()
+		second.NpcMove()
+
+		firstNpcs, secondNpcs := first.sortedBeings(), second.sortedBeings()
+		for j := range firstNpcs {
+			assert.Equal(t, *firstNpcs[j].Location, *secondNpcs[j].Location, "Beings should follow the same trajectory on tick %d", i)
+		}
+	}
+}
+
+func TestDifferentSeedsProduceDifferentMaps(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	first := SeededWorld(logger, 1)
+	second := SeededWorld(logger, 2)
+
+	assert.NotEqual(t, first.Geography, second.Geography, "Different seeds should produce different maps")
+}
+
+func TestHash(t *testing.T) {
+	logger := log.New(io.Discard, "", log.LstdFlags)
+	first := SeededWorld(logger, 11)
+	second := SeededWorld(logger, 11)
+
+	assert.Equal(t, first.Hash(), second.Hash(), "Identical worlds should hash the same")
+
+	first.Tick()
+	assert.NotEqual(t, first.Hash(), second.Hash(), "Hash should change when time passes")
+
+	second.Tick()
+	second.Player.Direction = object.South
+	assert.NotEqual(t, first.Hash(), second.Hash(), "Hash should change when a being turns")
+}